@@ -2,6 +2,7 @@ package cache
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,16 +14,49 @@ const (
 type Item struct {
 	value      interface{}
 	expiration int64
+
+	// negative tells that the lookup has found no value for the key.
+	negative bool
+	// refreshing is set once the value is being refreshed in background.
+	refreshing int32
+}
+
+func (item *Item) expired(now int64) bool {
+	return item.expiration > 0 && now > item.expiration
+}
+
+// call is the lookup in flight shared by the concurrent Get of the same key.
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	ok  bool
 }
 
 type TTLCache struct {
-	items      sync.Map
-	defaultTTL time.Duration
-	lookupFunc func(interface{}) (interface{}, bool)
+	items        sync.Map
+	defaultTTL   time.Duration
+	negativeTTL  time.Duration
+	refreshAhead time.Duration
+	lookupFunc   func(interface{}) (interface{}, bool)
+
+	mu    sync.Mutex
+	calls map[interface{}]*call
+	// gen is bumped by Del and Flush not to store the values looked up before them.
+	// gens and flushed keep the generation of the last Del of the key and of the last Flush,
+	// only as long as the lookups started before them are in flight.
+	gen      uint64
+	gens     map[interface{}]uint64
+	flushed  uint64
+	inflight map[uint64]int
 }
 
 func NewTTLCache(defaultTTL time.Duration) *TTLCache {
-	c := &TTLCache{defaultTTL: defaultTTL}
+	c := &TTLCache{
+		defaultTTL: defaultTTL,
+		calls:      make(map[interface{}]*call),
+		gens:       make(map[interface{}]uint64),
+		inflight:   make(map[uint64]int),
+	}
 
 	// GC
 	go func() {
@@ -34,29 +68,178 @@ func NewTTLCache(defaultTTL time.Duration) *TTLCache {
 		for now := range time.Tick(interval) {
 			c.items.Range(func(key, val interface{}) bool {
 				item := val.(*Item)
-				if item.expiration > 0 && now.UnixNano() > item.expiration {
-					c.Del(key)
+				if item.expired(now.UnixNano()) {
+					c.items.Delete(key)
 				}
 				return true
 			})
+			c.pruneGenerations()
 		}
 	}()
 
 	return c
 }
 
+// Get returns the value of the key, which is looked up with the lookup function if not found or expired.
+// The key not found by the lookup is cached as negative for the negative TTL,
+// and the value close to the expiration is refreshed in background.
 func (c *TTLCache) Get(key interface{}) (interface{}, bool) {
+	now := time.Now().UnixNano()
+
 	val, ok := c.items.Load(key)
-	if !ok && c.lookupFunc != nil {
-		return c.lookupFunc(key)
+	if ok && val.(*Item).expired(now) {
+		c.items.Delete(key)
+		ok = false
+	}
+
+	if !ok {
+		if c.lookupFunc != nil {
+			return c.lookup(key)
+		}
+		return nil, false
+	}
+
+	item := val.(*Item)
+	if item.negative {
+		return nil, false
+	}
+
+	if c.lookupFunc != nil && c.refreshAhead > 0 && item.expiration > 0 &&
+		now+int64(c.refreshAhead) > item.expiration && atomic.CompareAndSwapInt32(&item.refreshing, 0, 1) {
+		go c.refresh(key)
 	}
 
-	item, ok := val.(*Item)
+	return item.value, true
+}
+
+// Peek returns the value of the key only if it is cached, without looking it up.
+func (c *TTLCache) Peek(key interface{}) (interface{}, bool) {
+	val, ok := c.items.Load(key)
 	if !ok {
 		return nil, false
 	}
 
-	return item.value, ok
+	item := val.(*Item)
+	if item.negative || item.expired(time.Now().UnixNano()) {
+		return nil, false
+	}
+
+	return item.value, true
+}
+
+// lookup looks up the key with the lookup function.
+// The concurrent lookups of the same key are collapsed into one.
+func (c *TTLCache) lookup(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	if cl, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		cl.wg.Wait()
+		return cl.val, cl.ok
+	}
+
+	cl := &call{}
+	cl.wg.Add(1)
+	c.calls[key] = cl
+	gen := c.begin()
+	c.mu.Unlock()
+
+	cl.val, cl.ok = c.lookupFunc(key)
+
+	// The value is not stored if the key is invalidated while it is looked up
+	c.mu.Lock()
+	if !c.invalidated(key, gen) {
+		switch {
+		case cl.ok:
+			c.SetWithExpiration(key, cl.val, DefaultExpiration)
+		case c.negativeTTL > 0:
+			c.items.Store(key, &Item{expiration: time.Now().Add(c.negativeTTL).UnixNano(), negative: true})
+		}
+	}
+	delete(c.calls, key)
+	c.end(gen)
+	c.mu.Unlock()
+	cl.wg.Done()
+
+	return cl.val, cl.ok
+}
+
+// refresh renews the value of the key before it expires.
+// The value is left to expire if the lookup fails.
+func (c *TTLCache) refresh(key interface{}) {
+	c.mu.Lock()
+	gen := c.begin()
+	c.mu.Unlock()
+
+	val, ok := c.lookupFunc(key)
+
+	c.mu.Lock()
+	if ok && !c.invalidated(key, gen) {
+		c.SetWithExpiration(key, val, DefaultExpiration)
+	}
+	c.end(gen)
+	c.mu.Unlock()
+}
+
+// Load stores the values given to set by fn, such as to warm up the cache in bulk,
+// but the values of the keys deleted or flushed while fn runs.
+func (c *TTLCache) Load(fn func(set func(key, val interface{})) error) error {
+	c.mu.Lock()
+	gen := c.begin()
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.end(gen)
+		c.mu.Unlock()
+	}()
+
+	return fn(func(key, val interface{}) {
+		c.mu.Lock()
+		if !c.invalidated(key, gen) {
+			c.SetWithExpiration(key, val, DefaultExpiration)
+		}
+		c.mu.Unlock()
+	})
+}
+
+// begin returns the generation which the lookup starts at, which must be called with mu held.
+func (c *TTLCache) begin() uint64 {
+	c.inflight[c.gen]++
+	return c.gen
+}
+
+// end finishes the lookup started at the generation, which must be called with mu held.
+// The generations of the keys are no longer needed once no lookup is in flight.
+func (c *TTLCache) end(gen uint64) {
+	if c.inflight[gen]--; c.inflight[gen] == 0 {
+		delete(c.inflight, gen)
+	}
+	if len(c.inflight) == 0 && len(c.gens) > 0 {
+		c.gens = make(map[interface{}]uint64)
+	}
+}
+
+// invalidated tells if the key is deleted or flushed after the generation, which must be called with mu held.
+func (c *TTLCache) invalidated(key interface{}, gen uint64) bool {
+	return c.flushed > gen || c.gens[key] > gen
+}
+
+// pruneGenerations deletes the generations of the keys deleted before all the lookups in flight.
+func (c *TTLCache) pruneGenerations() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	oldest := c.gen
+	for gen := range c.inflight {
+		if gen < oldest {
+			oldest = gen
+		}
+	}
+	for key, gen := range c.gens {
+		if gen <= oldest {
+			delete(c.gens, key)
+		}
+	}
 }
 
 func (c *TTLCache) GetAndResetExpiration(key interface{}, ttl time.Duration) (interface{}, bool) {
@@ -75,21 +258,54 @@ func (c *TTLCache) Set(key, val interface{}) {
 func (c *TTLCache) SetWithExpiration(key, val interface{}, ttl time.Duration) {
 	var expiration int64
 
-	switch {
-	case ttl == 0:
-		expiration = time.Now().Add(c.defaultTTL).UnixNano()
-	case ttl >= 1:
+	// The default TTL may be NoExpiration
+	if ttl == DefaultExpiration {
+		ttl = c.defaultTTL
+	}
+	if ttl >= 1 {
 		expiration = time.Now().Add(ttl).UnixNano()
 	}
 
-	item := &Item{val, expiration}
+	item := &Item{value: val, expiration: expiration}
 	c.items.Store(key, item)
 }
 
+// Del deletes the key, discarding the value of the lookup in flight.
 func (c *TTLCache) Del(key interface{}) {
+	c.mu.Lock()
+	c.gen++
+	if len(c.inflight) > 0 {
+		c.gens[key] = c.gen
+	}
 	c.items.Delete(key)
+	c.mu.Unlock()
+}
+
+// Flush deletes all the items to look them up again, discarding the values of the lookups in flight.
+func (c *TTLCache) Flush() {
+	c.mu.Lock()
+	c.gen++
+	c.flushed = c.gen
+	c.gens = make(map[interface{}]uint64)
+	c.items.Range(func(key, val interface{}) bool {
+		c.items.Delete(key)
+		return true
+	})
+	c.mu.Unlock()
 }
 
+// SetLookupFunc sets the function to look up the key not found in the cache.
+// The value found is cached with the default TTL.
 func (c *TTLCache) SetLookupFunc(fn func(interface{}) (interface{}, bool)) {
 	c.lookupFunc = fn
 }
+
+// SetNegativeExpiration sets the TTL of the keys not found by the lookup function, 0 to disable.
+func (c *TTLCache) SetNegativeExpiration(ttl time.Duration) {
+	c.negativeTTL = ttl
+}
+
+// SetRefreshAhead sets the time before the expiration to refresh the value with the lookup function, 0 to disable.
+func (c *TTLCache) SetRefreshAhead(d time.Duration) {
+	c.refreshAhead = d
+}
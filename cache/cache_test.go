@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -38,6 +40,36 @@ func TestSetAndExpire(t *testing.T) {
 	}
 }
 
+func TestLookupNoExpiration(t *testing.T) {
+	var count int32
+	ttlCache := NewTTLCache(NoExpiration)
+	ttlCache.SetLookupFunc(func(key interface{}) (interface{}, bool) {
+		atomic.AddInt32(&count, 1)
+		return key, true
+	})
+
+	ttlCache.Get("key1")
+	time.Sleep(10 * time.Millisecond)
+	ttlCache.Get("key1")
+
+	if n := atomic.LoadInt32(&count); n != 1 {
+		t.Errorf("The key 'key1' should be cached without the expiration, but was looked up %d times", n)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	ttlCache := NewTTLCache(NoExpiration)
+	ttlCache.Set("key1", 1)
+	ttlCache.Set("key2", 2)
+	ttlCache.Flush()
+
+	for _, key := range []string{"key1", "key2"} {
+		if val, ok := ttlCache.Get(key); ok {
+			t.Errorf("The value for the key '%s' should be nil, but was '%v'", key, val)
+		}
+	}
+}
+
 func TestLookupFunc(t *testing.T) {
 	ttlCache := NewTTLCache(NoExpiration)
 	ttlCache.SetLookupFunc(func(key interface{}) (interface{}, bool) {
@@ -62,3 +94,233 @@ func TestLookupFunc(t *testing.T) {
 		}
 	}
 }
+
+func TestNegativeExpiration(t *testing.T) {
+	var count int32
+	ttlCache := NewTTLCache(NoExpiration)
+	ttlCache.SetNegativeExpiration(500 * time.Millisecond)
+	ttlCache.SetLookupFunc(func(key interface{}) (interface{}, bool) {
+		atomic.AddInt32(&count, 1)
+		return nil, false
+	})
+
+	for i := 0; i < 3; i++ {
+		if val, ok := ttlCache.Get("key1"); ok {
+			t.Errorf("The value for the key 'key1' should be nil, but was '%v'", val)
+		}
+	}
+	if n := atomic.LoadInt32(&count); n != 1 {
+		t.Errorf("The key 'key1' should be looked up once, but was %d times", n)
+	}
+
+	time.Sleep(time.Second)
+
+	ttlCache.Get("key1")
+	if n := atomic.LoadInt32(&count); n != 2 {
+		t.Errorf("The key 'key1' should be looked up again after the negative TTL, but was %d times", n)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	var count int32
+	ttlCache := NewTTLCache(NoExpiration)
+	ttlCache.SetLookupFunc(func(key interface{}) (interface{}, bool) {
+		atomic.AddInt32(&count, 1)
+		return key, true
+	})
+
+	if val, ok := ttlCache.Peek("key1"); ok {
+		t.Errorf("The value for the key 'key1' should be nil, but was '%v'", val)
+	}
+	if n := atomic.LoadInt32(&count); n != 0 {
+		t.Errorf("The key 'key1' should not be looked up by Peek, but was %d times", n)
+	}
+
+	ttlCache.Get("key1")
+	if val, ok := ttlCache.Peek("key1"); !ok || val != "key1" {
+		t.Errorf("The value for the key 'key1' should be 'key1', but was '%v'", val)
+	}
+}
+
+func TestLookupCollapse(t *testing.T) {
+	var count int32
+	ttlCache := NewTTLCache(NoExpiration)
+	ttlCache.SetLookupFunc(func(key interface{}) (interface{}, bool) {
+		atomic.AddInt32(&count, 1)
+		time.Sleep(100 * time.Millisecond)
+		return key, true
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if val, ok := ttlCache.Get("key1"); !ok || val != "key1" {
+				t.Errorf("The value for the key 'key1' should be 'key1', but was '%v'", val)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&count); n != 1 {
+		t.Errorf("The concurrent lookups of the key 'key1' should be collapsed, but was %d times", n)
+	}
+}
+
+func TestRefreshAhead(t *testing.T) {
+	var count int32
+	ttlCache := NewTTLCache(time.Second)
+	ttlCache.SetRefreshAhead(800 * time.Millisecond)
+	ttlCache.SetLookupFunc(func(key interface{}) (interface{}, bool) {
+		return atomic.AddInt32(&count, 1), true
+	})
+
+	if val, ok := ttlCache.Get("key1"); !ok || val != int32(1) {
+		t.Errorf("The value for the key 'key1' should be '1', but was '%v'", val)
+	}
+
+	// The value within the refresh window is returned as is and refreshed in background
+	time.Sleep(500 * time.Millisecond)
+	if val, ok := ttlCache.Get("key1"); !ok || val != int32(1) {
+		t.Errorf("The value for the key 'key1' should be '1', but was '%v'", val)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if val, ok := ttlCache.Get("key1"); !ok || val != int32(2) {
+		t.Errorf("The value for the key 'key1' should be refreshed to '2', but was '%v'", val)
+	}
+}
+
+func TestDelWhileLookup(t *testing.T) {
+	var value atomic.Value
+	value.Store("old")
+
+	started := make(chan struct{}, 1)
+	ttlCache := NewTTLCache(NoExpiration)
+	ttlCache.SetLookupFunc(func(key interface{}) (interface{}, bool) {
+		v := value.Load()
+		started <- struct{}{}
+		time.Sleep(200 * time.Millisecond)
+		return v, true
+	})
+
+	for _, invalidate := range []func(){func() { ttlCache.Del("key1") }, ttlCache.Flush} {
+		value.Store("old")
+		ttlCache.Del("key1")
+
+		done := make(chan struct{})
+		go func() {
+			ttlCache.Get("key1")
+			close(done)
+		}()
+
+		// The key is changed and invalidated while the old value is looked up
+		<-started
+		value.Store("new")
+		invalidate()
+		<-done
+
+		go func() { <-started }()
+		if val, ok := ttlCache.Get("key1"); !ok || val != "new" {
+			t.Errorf("The value for the key 'key1' should be 'new' after the invalidation, but was '%v'", val)
+		}
+	}
+}
+
+func TestDelWhileRefresh(t *testing.T) {
+	var count int32
+	ttlCache := NewTTLCache(time.Second)
+	ttlCache.SetRefreshAhead(800 * time.Millisecond)
+	ttlCache.SetLookupFunc(func(key interface{}) (interface{}, bool) {
+		n := atomic.AddInt32(&count, 1)
+		if n == 2 {
+			// The refresh is slow to be overtaken by the invalidation
+			time.Sleep(300 * time.Millisecond)
+		}
+		return n, true
+	})
+
+	ttlCache.Get("key1")
+	time.Sleep(500 * time.Millisecond)
+
+	// Trigger the refresh and invalidate the key while it is in flight
+	ttlCache.Get("key1")
+	time.Sleep(50 * time.Millisecond)
+	ttlCache.Del("key1")
+
+	if val, ok := ttlCache.Get("key1"); !ok || val != int32(3) {
+		t.Errorf("The value for the key 'key1' should be looked up again to '3', but was '%v'", val)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+	if val, ok := ttlCache.Get("key1"); !ok || val != int32(3) {
+		t.Errorf("The value for the key 'key1' should not be overwritten by the refresh, but was '%v'", val)
+	}
+}
+
+func TestDelWhileLoad(t *testing.T) {
+	ttlCache := NewTTLCache(NoExpiration)
+
+	err := ttlCache.Load(func(set func(key, val interface{})) error {
+		set("key1", "old")
+
+		// The key is changed and invalidated while the old values are loaded
+		ttlCache.Set("key2", "new")
+		ttlCache.Del("key2")
+		set("key2", "old")
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Failed to load the values:", err)
+	}
+
+	if val, ok := ttlCache.Get("key1"); !ok || val != "old" {
+		t.Errorf("The value for the key 'key1' should be 'old', but was '%v'", val)
+	}
+	if val, ok := ttlCache.Get("key2"); ok {
+		t.Errorf("The value for the key 'key2' should not be loaded after the invalidation, but was '%v'", val)
+	}
+}
+
+func TestGenerationPrune(t *testing.T) {
+	ttlCache := NewTTLCache(NoExpiration)
+
+	// The generations are not kept with no lookup in flight
+	ttlCache.Del("key1")
+	if n := len(ttlCache.gens); n != 0 {
+		t.Errorf("The generations should be '0' with no lookup in flight, but was '%d'", n)
+	}
+
+	started, finished := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	ttlCache.Load(func(set func(key, val interface{})) error {
+		ttlCache.Del("key1")
+		ttlCache.Del("key2")
+
+		// Another lookup starts after the invalidation and outlives this one
+		go func() {
+			ttlCache.Load(func(set func(key, val interface{})) error {
+				close(started)
+				<-finished
+				return nil
+			})
+			close(done)
+		}()
+		<-started
+		return nil
+	})
+
+	// The generations before the oldest lookup in flight are pruned
+	ttlCache.Del("key3")
+	ttlCache.pruneGenerations()
+	if n := len(ttlCache.gens); n != 1 {
+		t.Errorf("The generations should be pruned to '1', but was '%d'", n)
+	}
+
+	close(finished)
+	<-done
+	if n := len(ttlCache.gens); n != 0 {
+		t.Errorf("The generations should be '0' after the lookups, but was '%d'", n)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/google/gopacket"
@@ -14,7 +15,10 @@ import (
 	"github.com/google/gopacket/pcapgo"
 	"github.com/jessevdk/go-flags"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/haccht/vplsbh/l2vpn"
 	pb "github.com/haccht/vplsbh/pkg/grpc"
 )
 
@@ -29,7 +33,11 @@ type cmdOption struct {
 	DomainFilter string `short:"d" long:"domain"    description:"filter packets by Bridge-Domain name" value-name:"<bdname>"`
 	PacketCount  uint   `short:"c" long:"count"     description:"exit after reading specified number of packets" value-name:"<count>"`
 	Duration     uint   `short:"t" long:"duration"  description:"exit after specified seconds have elapsed" value-name:"<seconds>"`
-	WriteFile    string `short:"w" long:"write"     description:"write packets to the pcap file" value-name:"<filepath>"`
+	WriteFile    string `short:"w" long:"write"     description:"write packets to the pcapng file" value-name:"<filepath>"`
+	SVLANFilter  uint32 `long:"svlan"               description:"filter packets by inner S-VLAN" value-name:"<vid>"`
+	CVLANFilter  uint32 `long:"cvlan"               description:"filter packets by inner C-VLAN" value-name:"<vid>"`
+	OAM          bool   `long:"oam"                 description:"capture PW OAM messages instead of BUM frames"`
+	Interface    string `long:"interface"           description:"filter packets by the interface of bumstream" value-name:"<interface>"`
 }
 
 func NewCmdOption(args []string) (*cmdOption, error) {
@@ -42,6 +50,165 @@ func NewCmdOption(args []string) (*cmdOption, error) {
 	return &opt, nil
 }
 
+// packetWriter writes the packets to the pcapng file.
+// The outer encapsulation and the interface of bumstream, which are not carried in the packet data,
+// are recorded as the description of the pcapng interface which the packet is written on.
+type packetWriter struct {
+	*pcapgo.NgWriter
+	ifaces map[string]int
+}
+
+func newPacketWriter(w io.Writer) (*packetWriter, error) {
+	intf := pcapgo.NgInterface{Name: "bumcapture", LinkType: layers.LinkTypeRaw, SnapLength: snapshotLen}
+	ng, err := pcapgo.NewNgWriterInterface(w, intf, pcapgo.NgWriterOptions{SectionInfo: pcapgo.NgSectionInfo{Application: "bumcapture"}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &packetWriter{NgWriter: ng, ifaces: map[string]int{"": 0}}, nil
+}
+
+// describeOuter returns the description of the pcapng interface recording the outer encapsulation of the packet.
+// The ERSPAN timestamp is left out not to add the interface per packet.
+func describeOuter(recv *pb.Packet) (string, error) {
+	if recv.Outer == nil && recv.Vni == 0 && recv.Interface == "" {
+		return "", nil
+	}
+
+	p := &pb.Packet{Interface: recv.Interface, Vni: recv.Vni}
+	if recv.Outer != nil {
+		p.Outer = proto.Clone(recv.Outer).(*pb.Outer)
+		p.Outer.Timestamp = 0
+	}
+
+	b, err := protojson.Marshal(p)
+	return string(b), err
+}
+
+// writePacket writes the packet on the interface describing its outer encapsulation, which is added at the first use.
+func (w *packetWriter) writePacket(recv *pb.Packet, ci gopacket.CaptureInfo, data []byte) error {
+	desc, err := describeOuter(recv)
+	if err != nil {
+		return err
+	}
+
+	id, ok := w.ifaces[desc]
+	if !ok {
+		intf := pcapgo.NgInterface{Name: recv.Interface, Description: desc, LinkType: layers.LinkTypeRaw, SnapLength: snapshotLen}
+		if id, err = w.AddInterface(intf); err != nil {
+			return err
+		}
+		w.ifaces[desc] = id
+	}
+
+	ci.InterfaceIndex = id
+	return w.WritePacket(ci, data)
+}
+
+// serializeBackbone prepends the backbone MACs, the B-TAG and the I-TAG to the customer frame.
+func serializeBackbone(buf gopacket.SerializeBuffer, b *pb.Backbone) error {
+	srcMAC, _ := net.ParseMAC(b.Srcmac)
+	dstMAC, _ := net.ParseMAC(b.Dstmac)
+
+	ls := []gopacket.SerializableLayer{
+		&layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: l2vpn.EthernetTypePBB},
+		&l2vpn.PBB{ISID: b.Isid},
+	}
+	if b.Bvid != 0 {
+		ls = []gopacket.SerializableLayer{
+			&layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeQinQ},
+			&layers.Dot1Q{VLANIdentifier: uint16(b.Bvid), Type: l2vpn.EthernetTypePBB},
+			&l2vpn.PBB{ISID: b.Isid},
+		}
+	}
+
+	for i := len(ls) - 1; i >= 0; i-- {
+		if err := ls[i].SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encapsulate returns the received frame carried over IP from the peer and the layer type of the IP header.
+// The IPv6 header is used for the IPv6 peer, and the unspecified address stands for the peer of the frame
+// with the unknown label, which is published without the peer ID.
+func encapsulate(recv *pb.Packet) ([]byte, gopacket.LayerType, error) {
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{}
+
+	bytes, err := buf.PrependBytes(len(recv.Data))
+	if err != nil {
+		return nil, 0, err
+	}
+	copy(bytes, recv.Data)
+
+	// Restore the backbone frame carrying the customer frame of PBB-VPLS
+	if b := recv.Backbone; b != nil {
+		if err := serializeBackbone(buf, b); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	ipProto := layers.IPProtocolEtherIP
+	if len(recv.Labels) > 0 {
+		// Restore the label stack and the control word in front of the PW payload
+		// so that the frame can be replayed with its original encapsulation.
+		ipProto = layers.IPProtocolMPLSInIP
+		if recv.Controlword && recv.Kind == pb.PacketKind_DATA {
+			cw := &l2vpn.PWMCW{SequenceNumber: uint16(recv.Sequence)}
+			if err := cw.SerializeTo(buf, opts); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		vpls := &l2vpn.VPLS{Stack: make([]l2vpn.LabelStackEntry, len(recv.Labels))}
+		for i, e := range recv.Labels {
+			vpls.Stack[i] = l2vpn.LabelStackEntry{Label: e.Label, TrafficClass: uint8(e.Tc), TTL: uint8(e.Ttl)}
+		}
+		if err := vpls.SerializeTo(buf, opts); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		etherip := &layers.EtherIP{Version: 3}
+		if bytes, err = buf.PrependBytes(2); err != nil {
+			return nil, 0, err
+		}
+		bytes[0] = (etherip.Version << 4)
+	}
+
+	peer := net.ParseIP(recv.Peerid)
+	if peer != nil && peer.To4() == nil {
+		ip := &layers.IPv6{
+			Version:    6,
+			HopLimit:   64,
+			NextHeader: ipProto,
+			SrcIP:      peer,
+			DstIP:      net.IPv6unspecified,
+		}
+		if err := ip.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+			return nil, 0, err
+		}
+		return buf.Bytes(), layers.LayerTypeIPv6, nil
+	}
+
+	if peer == nil {
+		peer = net.IPv4zero
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		Protocol: ipProto,
+		SrcIP:    peer,
+		DstIP:    net.IPv4zero,
+	}
+	if err := ip.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), layers.LayerTypeIPv4, nil
+}
+
 func main() {
 	opt, err := NewCmdOption(os.Args)
 	if err != nil {
@@ -52,7 +219,10 @@ func main() {
 		os.Exit(1)
 	}
 
-	var w *pcapgo.Writer
+	// The backbone frames of PBB-VPLS are restored with the I-TAG
+	l2vpn.Register()
+
+	var w *packetWriter
 	if opt.WriteFile != "" {
 		f, err := os.Create(opt.WriteFile)
 		if err != nil {
@@ -60,8 +230,10 @@ func main() {
 		}
 		defer f.Close()
 
-		w = pcapgo.NewWriter(f)
-		w.WriteFileHeader(snapshotLen, layers.LinkTypeIPv4)
+		if w, err = newPacketWriter(f); err != nil {
+			log.Fatalf("failed to write file: %v", err)
+		}
+		defer w.Flush()
 	}
 
 	conn, err := grpc.Dial(opt.Address, grpc.WithInsecure())
@@ -70,7 +242,10 @@ func main() {
 	}
 	defer conn.Close()
 
-	req := &pb.Request{Filter: opt.BPFFilter, Remote: opt.RemoteFilter, Domain: opt.DomainFilter}
+	req := &pb.Request{Filter: opt.BPFFilter, Remote: opt.RemoteFilter, Domain: opt.DomainFilter, Svlan: opt.SVLANFilter, Cvlan: opt.CVLANFilter, Interface: opt.Interface}
+	if opt.OAM {
+		req.Kind = pb.PacketKind_OAM
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	if opt.Duration != 0 {
 		ctx, cancel = context.WithTimeout(ctx, time.Second*time.Duration(opt.Duration))
@@ -93,48 +268,55 @@ func main() {
 			if ctx.Err() == context.DeadlineExceeded {
 				break
 			}
+			// The packets buffered by the writer are kept before exiting
+			if w != nil {
+				w.Flush()
+			}
 			log.Fatalf("stop receiving packets: %v", err)
 		}
 
-		ip := &layers.IPv4{
-			Version:  4,
-			IHL:      5,
-			TTL:      64,
-			Protocol: layers.IPProtocolEtherIP,
-			SrcIP:    net.ParseIP(recv.Peerid),
-			DstIP:    net.ParseIP("0.0.0.0"),
-		}
-		etherip := &layers.EtherIP{
-			Version: 3,
+		data, lt, err := encapsulate(recv)
+		if err != nil {
+			log.Printf("skip the packet: %v", err)
+			continue
 		}
 
-		buf := gopacket.NewSerializeBuffer()
-		opts := gopacket.SerializeOptions{}
-		var bytes []byte
+		// Decode the PW payload as the packet was received
+		layers.MPLSPayloadDecoder = &l2vpn.PWMCWDecoder{ControlWord: recv.Controlword}
+		packet := gopacket.NewPacket(data, lt, gopacket.Lazy)
+		md := packet.Metadata()
+		ci := gopacket.CaptureInfo{Timestamp: recv.Timestamp.AsTime(), CaptureLength: len(packet.Data()), Length: len(packet.Data())}
+		md.CaptureInfo = ci
 
-		if bytes, err = buf.PrependBytes(len(recv.Data)); err != nil {
-			break
+		stack := make([]string, len(recv.Labels))
+		for i, e := range recv.Labels {
+			stack[i] = fmt.Sprintf("%d/%d/%d(%s)", e.Label, e.Tc, e.Ttl, e.Kind)
 		}
-		copy(bytes, recv.Data)
 
-		if bytes, err = buf.PrependBytes(2); err != nil {
-			break
+		fmt.Printf("DOMAIN: %s, REMOTE: %s, LABEL: %d, STACK(LABEL/TC/TTL(KIND)): %s, INTERFACE: %s\n", recv.Domain, recv.Remote, recv.Label, strings.Join(stack, " "), recv.Interface)
+		if b := recv.Backbone; b != nil {
+			fmt.Printf("BACKBONE: %s > %s, B-VID: %d, I-SID: %d\n", b.Srcmac, b.Dstmac, b.Bvid, b.Isid)
 		}
-		bytes[0] = (etherip.Version << 4)
-
-		if err := ip.SerializeTo(buf, opts); err != nil {
-			break
+		if recv.Vni != 0 {
+			fmt.Printf("VNI: %d\n", recv.Vni)
+		}
+		if recv.Service == pb.ServiceType_EVPN {
+			fmt.Printf("SERVICE: %s, ESI: %s\n", recv.Service, recv.Esi)
+		}
+		if o := recv.Outer; o != nil && (o.Encap != pb.EncapType_ETHERNET || len(o.Vlans) > 0) {
+			fmt.Printf("OUTER: %s, VLANS: %v, SRC: %s, DST: %s\n", l2vpn.EncapType(o.Encap), o.Vlans, o.Srcip, o.Dstip)
+			if o.Encap == pb.EncapType_ERSPAN {
+				fmt.Printf("ERSPAN: VERSION: %d, SESSION: %d, TIMESTAMP: %d\n", o.Erspanversion, o.Sessionid, o.Timestamp)
+			}
+		}
+		if recv.Kind == pb.PacketKind_OAM {
+			fmt.Printf("CHANNEL: %s\n", l2vpn.PWACHChannelType(recv.Channel))
 		}
-
-		packet := gopacket.NewPacket(buf.Bytes(), layers.LayerTypeIPv4, gopacket.Lazy)
-		md := packet.Metadata()
-		ci := gopacket.CaptureInfo{Timestamp: recv.Timestamp.AsTime(), CaptureLength: len(packet.Data()), Length: len(packet.Data())}
-		md.CaptureInfo = ci
-
-		fmt.Printf("DOMAIN: %s, REMOTE: %s, LABEL: %d\n", recv.Domain, recv.Remote, recv.Label)
 		fmt.Println(packet)
 		if w != nil {
-			w.WritePacket(packet.Metadata().CaptureInfo, packet.Data())
+			if err := w.writePacket(recv, packet.Metadata().CaptureInfo, packet.Data()); err != nil {
+				log.Printf("failed to write packet: %v", err)
+			}
 		}
 
 		np++
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/haccht/vplsbh/pkg/grpc"
+)
+
+var testFrame = []byte{
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x5e, 0x00, 0x53, 0x01, 0x08, 0x06,
+	0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01,
+}
+
+func TestEncapsulateUnknown(t *testing.T) {
+	recv := &pb.Packet{
+		Domain: "unknown",
+		Remote: "unknown",
+		Label:  1000,
+		Labels: []*pb.LabelStackEntry{{Label: 1000, Ttl: 255}},
+		Data:   testFrame,
+	}
+
+	data, lt, err := encapsulate(recv)
+	if err != nil {
+		t.Fatalf("The packet with the unknown label should be encapsulated, but was '%v'", err)
+	}
+	if lt != layers.LayerTypeIPv4 {
+		t.Fatalf("The packet without the peer ID should be IPv4, but was '%v'", lt)
+	}
+
+	packet := gopacket.NewPacket(data, lt, gopacket.Default)
+	ip, ok := packet.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+	if !ok {
+		t.Fatalf("The packet should have the IPv4 header, but was '%v'", packet)
+	}
+	if !ip.SrcIP.Equal(net.IPv4zero) {
+		t.Errorf("The source IP should be '%v', but was '%v'", net.IPv4zero, ip.SrcIP)
+	}
+	if ip.Protocol != layers.IPProtocolMPLSInIP {
+		t.Errorf("The protocol should be '%v', but was '%v'", layers.IPProtocolMPLSInIP, ip.Protocol)
+	}
+	if int(ip.Length) != len(data) {
+		t.Errorf("The length should be '%d', but was '%d'", len(data), ip.Length)
+	}
+}
+
+func TestEncapsulateIPv6(t *testing.T) {
+	recv := &pb.Packet{
+		Domain: "bd-5000",
+		Peerid: "2001:db8::1",
+		Vni:    5000,
+		Data:   testFrame,
+	}
+
+	data, lt, err := encapsulate(recv)
+	if err != nil {
+		t.Fatalf("The packet from the IPv6 peer should be encapsulated, but was '%v'", err)
+	}
+	if lt != layers.LayerTypeIPv6 {
+		t.Fatalf("The packet from the IPv6 peer should be IPv6, but was '%v'", lt)
+	}
+
+	packet := gopacket.NewPacket(data, lt, gopacket.Default)
+	ip, ok := packet.Layer(layers.LayerTypeIPv6).(*layers.IPv6)
+	if !ok {
+		t.Fatalf("The packet should have the IPv6 header, but was '%v'", packet)
+	}
+	if ip.SrcIP.String() != recv.Peerid {
+		t.Errorf("The source IP should be '%s', but was '%v'", recv.Peerid, ip.SrcIP)
+	}
+	if ip.NextHeader != layers.IPProtocolEtherIP {
+		t.Errorf("The next header should be '%v', but was '%v'", layers.IPProtocolEtherIP, ip.NextHeader)
+	}
+	if len(ip.Payload) != len(testFrame)+2 {
+		t.Errorf("The payload should be '%d' bytes, but was '%d'", len(testFrame)+2, len(ip.Payload))
+	}
+}
+
+func TestPacketWriter(t *testing.T) {
+	var b bytes.Buffer
+	w, err := newPacketWriter(&b)
+	if err != nil {
+		t.Fatal("Failed to write pcapng:", err)
+	}
+
+	outer := func(ts uint32) *pb.Outer {
+		return &pb.Outer{Encap: pb.EncapType_ERSPAN, Vlans: []uint32{100}, Srcmac: "cc:15:14:64:00:00", Dstmac: "cc:13:14:64:00:01", Erspanversion: 2, Timestamp: ts}
+	}
+	packets := []*pb.Packet{
+		{Interface: "eth0", Outer: outer(1), Data: testFrame},
+		{Interface: "eth0", Outer: outer(2), Data: testFrame},
+		{Interface: "eth1", Outer: outer(3), Data: testFrame},
+	}
+	for _, recv := range packets {
+		data, _, err := encapsulate(recv)
+		if err != nil {
+			t.Fatal("Failed to encapsulate packet:", err)
+		}
+		ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+		if err := w.writePacket(recv, ci, data); err != nil {
+			t.Fatal("Failed to write packet:", err)
+		}
+	}
+	w.Flush()
+
+	r, err := pcapgo.NewNgReader(&b, pcapgo.DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal("Failed to read pcapng:", err)
+	}
+	for i, recv := range packets {
+		_, ci, err := r.ReadPacketData()
+		if err != nil {
+			t.Fatal("Failed to read packet:", err)
+		}
+
+		intf, _ := r.Interface(ci.InterfaceIndex)
+		var meta pb.Packet
+		if err := protojson.Unmarshal([]byte(intf.Description), &meta); err != nil {
+			t.Fatalf("The packet %d should be on the interface recording the outer encapsulation, but was '%v'", i, err)
+		}
+		if meta.Interface != recv.Interface || meta.Outer.Srcmac != recv.Outer.Srcmac || len(meta.Outer.Vlans) != 1 {
+			t.Errorf("The record of the packet %d should be '%v', but was '%v'", i, recv.Outer, meta.Outer)
+		}
+	}
+
+	// The packets differing only by the ERSPAN timestamp share the interface
+	if r.NInterfaces() != 3 {
+		t.Errorf("The pcapng file should have '3' interfaces, but was '%d'", r.NInterfaces())
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/jessevdk/go-flags"
+
+	"github.com/haccht/vplsbh/l2vpn"
+)
+
+const (
+	snapshotLen = 65536
+	promiscuous = true
+)
+
+// Kinds of the BUM frames carried in the PW
+const (
+	modeARP       = "arp"
+	modeND        = "nd"
+	modeDHCP      = "dhcp"
+	modeBroadcast = "broadcast"
+)
+
+type cmdOption struct {
+	Interface      string   `short:"i" long:"interface" description:"Write packets to the interface" value-name:"<interface>"`
+	WriteFile      string   `short:"w" long:"write"     description:"Write packets to the pcap file" value-name:"<filepath>"`
+	Labels         []uint32 `short:"l" long:"label"     description:"PW label of a remote, repeat for multiple remotes" value-name:"<label>" required:"true"`
+	TransportLabel uint32   `short:"t" long:"transport" description:"Transport label above the PW label, 0 for none" value-name:"<label>" default:"16001"`
+	ControlWord    bool     `long:"cw"                  description:"Insert the control word with sequence numbers"`
+	Mode           string   `short:"m" long:"mode"      description:"Kind of the BUM frames" choice:"arp" choice:"nd" choice:"dhcp" choice:"broadcast" default:"arp"`
+	VLAN           uint16   `long:"vlan"                description:"Tag the BUM frames with the VLAN" value-name:"<vid>"`
+	Hosts          uint     `long:"hosts"               description:"Number of source hosts behind each remote" value-name:"<count>" default:"1"`
+	MACMove        bool     `long:"mac-move"            description:"Send the same source hosts from every remote"`
+	PacketCount    uint     `short:"c" long:"count"     description:"Number of packets to generate" value-name:"<count>" default:"100"`
+	Rate           uint     `long:"pps"                 description:"Packets per second to generate" value-name:"<pps>" default:"100"`
+	SrcMAC         string   `long:"src-mac"             description:"Source MAC of the mirrored P-PE frames" value-name:"<mac>" default:"cc:15:14:64:00:00"`
+	DstMAC         string   `long:"dst-mac"             description:"Destination MAC of the mirrored P-PE frames" value-name:"<mac>" default:"cc:13:14:64:00:01"`
+}
+
+func NewCmdOption(args []string) (*cmdOption, error) {
+	var opt cmdOption
+
+	_, err := flags.ParseArgs(&opt, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.Interface == "" && opt.WriteFile == "" {
+		return nil, fmt.Errorf("either '-i' or '-w' must be specified")
+	}
+
+	if opt.Hosts == 0 || opt.Rate == 0 {
+		return nil, fmt.Errorf("the hosts and the pps must be positive")
+	}
+
+	// The packets are paced by the interval in nanoseconds
+	if opt.Rate > uint(time.Second) {
+		return nil, fmt.Errorf("the pps must be at most %d", time.Second)
+	}
+
+	return &opt, nil
+}
+
+// packetWriter writes the generated packets to the interface or the pcap file.
+type packetWriter interface {
+	WritePacket(ci gopacket.CaptureInfo, data []byte) error
+}
+
+type handleWriter struct {
+	*pcap.Handle
+}
+
+func (w *handleWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	return w.WritePacketData(data)
+}
+
+// generator builds the mirrored P-PE frames carrying the BUM frames of the remotes.
+type generator struct {
+	opt    *cmdOption
+	srcMAC net.HardwareAddr
+	dstMAC net.HardwareAddr
+	seqs   []uint16
+}
+
+func newGenerator(opt *cmdOption) (*generator, error) {
+	srcMAC, err := net.ParseMAC(opt.SrcMAC)
+	if err != nil {
+		return nil, err
+	}
+
+	dstMAC, err := net.ParseMAC(opt.DstMAC)
+	if err != nil {
+		return nil, err
+	}
+
+	return &generator{opt: opt, srcMAC: srcMAC, dstMAC: dstMAC, seqs: make([]uint16, len(opt.Labels))}, nil
+}
+
+// hostMAC returns the MAC of the host. The host moves between the remotes with --mac-move.
+func (g *generator) hostMAC(remote, host int) net.HardwareAddr {
+	n := host
+	if !g.opt.MACMove {
+		n += remote * int(g.opt.Hosts)
+	}
+
+	return net.HardwareAddr{0x02, 0x00, 0x5e, byte(n >> 16), byte(n >> 8), byte(n)}
+}
+
+func hostIP(mac net.HardwareAddr) net.IP {
+	return net.IPv4(10, mac[3], mac[4], mac[5])
+}
+
+// hostIPv6 returns the link-local address of the host in the modified EUI-64 format.
+func hostIPv6(mac net.HardwareAddr) net.IP {
+	return net.IP{0xfe, 0x80, 0, 0, 0, 0, 0, 0, mac[0] ^ 0x02, mac[1], mac[2], 0xff, 0xfe, mac[3], mac[4], mac[5]}
+}
+
+// nextSequence returns the sequence number of the remote, which wraps from 65535 to 1 (RFC 4385).
+func (g *generator) nextSequence(remote int) uint16 {
+	g.seqs[remote]++
+	if g.seqs[remote] == 0 {
+		g.seqs[remote] = 1
+	}
+	return g.seqs[remote]
+}
+
+// bumLayers returns the layers of the BUM frame sent by the host.
+func (g *generator) bumLayers(mac net.HardwareAddr) []gopacket.SerializableLayer {
+	eth := &layers.Ethernet{SrcMAC: mac, DstMAC: layers.EthernetBroadcast}
+
+	var ls []gopacket.SerializableLayer
+	var etype layers.EthernetType
+
+	switch g.opt.Mode {
+	case modeARP:
+		etype = layers.EthernetTypeARP
+		ls = []gopacket.SerializableLayer{
+			&layers.ARP{
+				AddrType:          layers.LinkTypeEthernet,
+				Protocol:          layers.EthernetTypeIPv4,
+				HwAddressSize:     6,
+				ProtAddressSize:   4,
+				Operation:         layers.ARPRequest,
+				SourceHwAddress:   mac,
+				SourceProtAddress: hostIP(mac).To4(),
+				DstHwAddress:      make([]byte, 6),
+				DstProtAddress:    net.IPv4(10, 0, 0, 254).To4(),
+			},
+		}
+	case modeND:
+		target := net.ParseIP("fe80::1")
+		group := net.ParseIP("ff02::1:ff00:1")
+
+		// The solicited-node multicast group of the target
+		eth.DstMAC = net.HardwareAddr{0x33, 0x33, group[12], group[13], group[14], group[15]}
+		etype = layers.EthernetTypeIPv6
+
+		ip6 := &layers.IPv6{Version: 6, HopLimit: 255, NextHeader: layers.IPProtocolICMPv6, SrcIP: hostIPv6(mac), DstIP: group}
+		icmp6 := &layers.ICMPv6{TypeCode: layers.CreateICMPv6TypeCode(layers.ICMPv6TypeNeighborSolicitation, 0)}
+		icmp6.SetNetworkLayerForChecksum(ip6)
+		ns := &layers.ICMPv6NeighborSolicitation{
+			TargetAddress: target,
+			Options:       layers.ICMPv6Options{{Type: layers.ICMPv6OptSourceAddress, Data: mac}},
+		}
+		ls = []gopacket.SerializableLayer{ip6, icmp6, ns}
+	case modeDHCP:
+		etype = layers.EthernetTypeIPv4
+
+		ip4 := &layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolUDP, SrcIP: net.IPv4zero, DstIP: net.IPv4bcast}
+		udp := &layers.UDP{SrcPort: 68, DstPort: 67}
+		udp.SetNetworkLayerForChecksum(ip4)
+		dhcp := &layers.DHCPv4{
+			Operation:    layers.DHCPOpRequest,
+			HardwareType: layers.LinkTypeEthernet,
+			HardwareLen:  6,
+			Xid:          uint32(mac[3])<<16 | uint32(mac[4])<<8 | uint32(mac[5]),
+			Flags:        0x8000,
+			ClientHWAddr: mac,
+			Options: layers.DHCPOptions{
+				layers.NewDHCPOption(layers.DHCPOptMessageType, []byte{byte(layers.DHCPMsgTypeDiscover)}),
+				layers.NewDHCPOption(layers.DHCPOptEnd, nil),
+			},
+		}
+		ls = []gopacket.SerializableLayer{ip4, udp, dhcp}
+	case modeBroadcast:
+		etype = layers.EthernetTypeIPv4
+
+		ip4 := &layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolUDP, SrcIP: hostIP(mac), DstIP: net.IPv4bcast}
+		udp := &layers.UDP{SrcPort: 9, DstPort: 9}
+		udp.SetNetworkLayerForChecksum(ip4)
+		ls = []gopacket.SerializableLayer{ip4, udp, gopacket.Payload(make([]byte, 64))}
+	}
+
+	if g.opt.VLAN != 0 {
+		eth.EthernetType = layers.EthernetTypeDot1Q
+		dot1q := &layers.Dot1Q{VLANIdentifier: g.opt.VLAN, Type: etype}
+		return append([]gopacket.SerializableLayer{eth, dot1q}, ls...)
+	}
+
+	eth.EthernetType = etype
+	return append([]gopacket.SerializableLayer{eth}, ls...)
+}
+
+// build serializes the n-th mirrored frame. The frames are sent from the remotes in turn.
+func (g *generator) build(buf gopacket.SerializeBuffer, n int) error {
+	remote := n % len(g.opt.Labels)
+	host := n / len(g.opt.Labels) % int(g.opt.Hosts)
+
+	vpls := &l2vpn.VPLS{}
+	if g.opt.TransportLabel != 0 {
+		vpls.Stack = append(vpls.Stack, l2vpn.LabelStackEntry{Label: g.opt.TransportLabel, TTL: 254})
+	}
+	vpls.Stack = append(vpls.Stack, l2vpn.LabelStackEntry{Label: g.opt.Labels[remote], StackBottom: true, TTL: 254})
+
+	ls := []gopacket.SerializableLayer{
+		&layers.Ethernet{SrcMAC: g.srcMAC, DstMAC: g.dstMAC, EthernetType: layers.EthernetTypeMPLSUnicast},
+		vpls,
+	}
+	if g.opt.ControlWord {
+		ls = append(ls, &l2vpn.PWMCW{SequenceNumber: g.nextSequence(remote)})
+	}
+	ls = append(ls, g.bumLayers(g.hostMAC(remote, host))...)
+
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	return gopacket.SerializeLayers(buf, opts, ls...)
+}
+
+func main() {
+	opt, err := NewCmdOption(os.Args)
+	if err != nil {
+		if fe, ok := err.(*flags.Error); ok && fe.Type == flags.ErrHelp {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	g, err := newGenerator(opt)
+	if err != nil {
+		log.Fatalf("failed to parse MAC: %v", err)
+	}
+
+	var w packetWriter
+	if opt.Interface != "" {
+		ha, err := pcap.OpenLive(opt.Interface, snapshotLen, promiscuous, pcap.BlockForever)
+		if err != nil {
+			log.Fatalf("failed to open pcap handle: %v", err)
+		}
+		defer ha.Close()
+
+		w = &handleWriter{ha}
+	} else {
+		f, err := os.Create(opt.WriteFile)
+		if err != nil {
+			log.Fatalf("failed to open file: %v", err)
+		}
+		defer f.Close()
+
+		pw := pcapgo.NewWriter(f)
+		pw.WriteFileHeader(snapshotLen, layers.LinkTypeEthernet)
+		w = pw
+	}
+
+	interval := time.Second / time.Duration(opt.Rate)
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	ts := time.Now()
+	buf := gopacket.NewSerializeBuffer()
+	for n := 0; n < int(opt.PacketCount); n++ {
+		if err := g.build(buf, n); err != nil {
+			log.Fatalf("failed to build packet: %v", err)
+		}
+
+		// Packets are paced on the interface, while they are timestamped at the rate in the pcap file
+		if opt.Interface != "" {
+			<-tick.C
+			ts = time.Now()
+		} else {
+			ts = ts.Add(interval)
+		}
+
+		data := buf.Bytes()
+		ci := gopacket.CaptureInfo{Timestamp: ts, CaptureLength: len(data), Length: len(data)}
+		if err := w.WritePacket(ci, data); err != nil {
+			log.Fatalf("failed to write packet: %v", err)
+		}
+	}
+
+	log.Printf("generated %d packets", opt.PacketCount)
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/jessevdk/go-flags"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/haccht/vplsbh/l2vpn"
+	pb "github.com/haccht/vplsbh/pkg/grpc"
+)
+
+const (
+	snapshotLen = 65536
+	promiscuous = true
+
+	// sourcePort is the UDP source port of the restored MPLS-over-UDP and VXLAN.
+	sourcePort layers.UDPPort = 49152
+)
+
+// pcapngMagic is the block type of the Section Header Block starting a pcapng file.
+var pcapngMagic = []byte{0x0a, 0x0d, 0x0d, 0x0a}
+
+// packetReader reads the packets from the pcap or pcapng file.
+type packetReader interface {
+	ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	LinkType() layers.LinkType
+}
+
+// openReader returns the reader of the pcap or pcapng file told by its magic.
+func openReader(r io.Reader) (packetReader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(magic, pcapngMagic) {
+		return pcapgo.NewNgReader(br, pcapgo.DefaultNgReaderOptions)
+	}
+	return pcapgo.NewReader(br)
+}
+
+type cmdOption struct {
+	ReadFile       string  `short:"r" long:"read"      description:"Read packets from the pcap or pcapng file written by bumcapture" value-name:"<filepath>" required:"true"`
+	Interface      string  `short:"i" long:"interface" description:"Write packets to the interface" value-name:"<interface>"`
+	WriteFile      string  `short:"w" long:"write"     description:"Write packets to the pcap file" value-name:"<filepath>"`
+	Speed          float64 `short:"s" long:"speed"     description:"Scale the original timing by the factor, 0 for no wait" value-name:"<factor>" default:"1"`
+	Label          uint32  `short:"l" long:"label"     description:"PW label of the frames captured without the label stack" value-name:"<label>"`
+	TransportLabel uint32  `short:"t" long:"transport" description:"Transport label above the PW label of the frames captured without the label stack" value-name:"<label>"`
+	SrcMAC         string  `long:"src-mac"             description:"Source MAC of the replayed P-PE frames without the outer header recorded" value-name:"<mac>" default:"cc:15:14:64:00:00"`
+	DstMAC         string  `long:"dst-mac"             description:"Destination MAC of the replayed P-PE frames without the outer header recorded" value-name:"<mac>" default:"cc:13:14:64:00:01"`
+}
+
+func NewCmdOption(args []string) (*cmdOption, error) {
+	var opt cmdOption
+
+	_, err := flags.ParseArgs(&opt, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.Interface == "" && opt.WriteFile == "" {
+		return nil, fmt.Errorf("either '-i' or '-w' must be specified")
+	}
+
+	if opt.Speed < 0 {
+		return nil, fmt.Errorf("the speed must not be negative")
+	}
+
+	return &opt, nil
+}
+
+// packetWriter writes the replayed packets to the interface or the pcap file.
+type packetWriter interface {
+	WritePacket(ci gopacket.CaptureInfo, data []byte) error
+}
+
+type handleWriter struct {
+	*pcap.Handle
+}
+
+func (w *handleWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	return w.WritePacketData(data)
+}
+
+// replayer rebuilds the mirrored P-PE frames from the packets captured by bumcapture.
+type replayer struct {
+	opt    *cmdOption
+	srcMAC net.HardwareAddr
+	dstMAC net.HardwareAddr
+
+	ip4 layers.IPv4
+	ip6 layers.IPv6
+	buf gopacket.SerializeBuffer
+
+	// metas is the record of the outer encapsulation per pcapng interface.
+	metas map[int]*pb.Packet
+}
+
+func newReplayer(opt *cmdOption) (*replayer, error) {
+	srcMAC, err := net.ParseMAC(opt.SrcMAC)
+	if err != nil {
+		return nil, err
+	}
+
+	dstMAC, err := net.ParseMAC(opt.DstMAC)
+	if err != nil {
+		return nil, err
+	}
+
+	return &replayer{opt: opt, srcMAC: srcMAC, dstMAC: dstMAC, buf: gopacket.NewSerializeBuffer(), metas: make(map[int]*pb.Packet)}, nil
+}
+
+// meta returns the outer encapsulation recorded by bumcapture as the description of the pcapng interface,
+// which is nil for the pcap file or the interface without the record.
+func (r *replayer) meta(pr packetReader, ci gopacket.CaptureInfo) *pb.Packet {
+	ng, ok := pr.(*pcapgo.NgReader)
+	if !ok {
+		return nil
+	}
+
+	if meta, ok := r.metas[ci.InterfaceIndex]; ok {
+		return meta
+	}
+
+	var meta *pb.Packet
+	if intf, err := ng.Interface(ci.InterfaceIndex); err == nil && intf.Description != "" {
+		meta = &pb.Packet{}
+		if err := protojson.Unmarshal([]byte(intf.Description), meta); err != nil {
+			log.Printf("ignore the record of the interface %d: %v", ci.InterfaceIndex, err)
+			meta = nil
+		}
+	}
+
+	r.metas[ci.InterfaceIndex] = meta
+	return meta
+}
+
+// rebuild returns the P-PE frame of the captured packet.
+// bumcapture stores the label stack and the control word after the IP header with MPLS-in-IP,
+// while the frames without the label stack, such as VXLAN, are stored with EtherIP.
+// The outer encapsulation recorded in the pcapng file is restored, otherwise the frame is sent over Ethernet
+// with the MACs given by the options.
+func (r *replayer) rebuild(data []byte, meta *pb.Packet) ([]byte, error) {
+	proto, payload, err := r.decodeIP(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var o *pb.Outer
+	if meta != nil {
+		o = meta.Outer
+	}
+
+	switch proto {
+	case layers.IPProtocolMPLSInIP:
+		var vpls l2vpn.VPLS
+		if err := vpls.DecodeFromBytes(payload, gopacket.NilDecodeFeedback); err != nil {
+			return nil, err
+		}
+
+		return r.serialize(o, gopacket.Payload(payload))
+	case layers.IPProtocolEtherIP:
+		if len(payload) < 2 {
+			return nil, fmt.Errorf("EtherIP header is truncated")
+		}
+
+		// VXLAN carries the BUM frame itself with the VNI
+		if o != nil && o.Encap == pb.EncapType_VXLAN {
+			return r.serialize(o, &layers.VXLAN{ValidIDFlag: true, VNI: meta.Vni}, gopacket.Payload(payload[2:]))
+		}
+
+		if r.opt.Label == 0 {
+			return nil, fmt.Errorf("the PW label is not specified with '-l'")
+		}
+
+		vpls := &l2vpn.VPLS{}
+		if r.opt.TransportLabel != 0 {
+			vpls.Stack = append(vpls.Stack, l2vpn.LabelStackEntry{Label: r.opt.TransportLabel, TTL: 254})
+		}
+		vpls.Stack = append(vpls.Stack, l2vpn.LabelStackEntry{Label: r.opt.Label, TTL: 254})
+
+		return r.serialize(o, vpls, gopacket.Payload(payload[2:]))
+	default:
+		return nil, fmt.Errorf("unexpected IP protocol %s", proto)
+	}
+}
+
+// serialize prepends the outer encapsulation to the layers from the label stack, or from the VXLAN header.
+// The Ethernet header carries the MPLS frame directly without the record, as well as for ERSPAN,
+// whose record is of the mirrored frame.
+func (r *replayer) serialize(o *pb.Outer, ls ...gopacket.SerializableLayer) ([]byte, error) {
+	eth := &layers.Ethernet{SrcMAC: r.srcMAC, DstMAC: r.dstMAC}
+	encap := pb.EncapType_ETHERNET
+
+	var vlans []uint32
+	var srcIP, dstIP net.IP
+	if o != nil {
+		if mac, err := net.ParseMAC(o.Srcmac); err == nil {
+			eth.SrcMAC = mac
+		}
+		if mac, err := net.ParseMAC(o.Dstmac); err == nil {
+			eth.DstMAC = mac
+		}
+
+		encap, vlans = o.Encap, o.Vlans
+		srcIP, dstIP = net.ParseIP(o.Srcip), net.ParseIP(o.Dstip)
+	}
+
+	// The tunnel over IP in front of the label stack or the VXLAN header
+	var tunnel []gopacket.SerializableLayer
+	switch encap {
+	case pb.EncapType_GRE:
+		tunnel = append(tunnel, &layers.GRE{Protocol: layers.EthernetTypeMPLSUnicast})
+	case pb.EncapType_UDP:
+		tunnel = append(tunnel, &layers.UDP{SrcPort: sourcePort, DstPort: l2vpn.UDPPortMPLS})
+	case pb.EncapType_VXLAN:
+		tunnel = append(tunnel, &layers.UDP{SrcPort: sourcePort, DstPort: l2vpn.UDPPortVXLAN})
+	}
+
+	etherType := layers.EthernetTypeMPLSUnicast
+	if tunnel != nil {
+		if srcIP == nil || dstIP == nil {
+			return nil, fmt.Errorf("the tunnel endpoints of %s are not recorded", l2vpn.EncapType(encap))
+		}
+
+		var ip gopacket.NetworkLayer
+		protocol := layers.IPProtocolGRE
+		if encap != pb.EncapType_GRE {
+			protocol = layers.IPProtocolUDP
+		}
+
+		if srcIP.To4() != nil {
+			etherType = layers.EthernetTypeIPv4
+			ip = &layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: protocol, SrcIP: srcIP.To4(), DstIP: dstIP.To4()}
+		} else {
+			etherType = layers.EthernetTypeIPv6
+			ip = &layers.IPv6{Version: 6, HopLimit: 64, NextHeader: protocol, SrcIP: srcIP, DstIP: dstIP}
+		}
+		if udp, ok := tunnel[0].(*layers.UDP); ok {
+			udp.SetNetworkLayerForChecksum(ip)
+		}
+		tunnel = append([]gopacket.SerializableLayer{ip.(gopacket.SerializableLayer)}, tunnel...)
+	}
+
+	// The outer tags from the outermost, which are of 802.1ad but the innermost
+	headers := []gopacket.SerializableLayer{eth}
+	next := &eth.EthernetType
+	for i, vid := range vlans {
+		if i < len(vlans)-1 {
+			*next = layers.EthernetTypeQinQ
+		} else {
+			*next = layers.EthernetTypeDot1Q
+		}
+
+		tag := &layers.Dot1Q{VLANIdentifier: uint16(vid)}
+		headers = append(headers, tag)
+		next = &tag.Type
+	}
+	*next = etherType
+
+	headers = append(headers, tunnel...)
+	headers = append(headers, ls...)
+
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	err := gopacket.SerializeLayers(r.buf, opts, headers...)
+	return r.buf.Bytes(), err
+}
+
+// decodeIP returns the protocol and the payload of the IPv4 or IPv6 header written by bumcapture.
+func (r *replayer) decodeIP(data []byte) (layers.IPProtocol, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, fmt.Errorf("IP header is truncated")
+	}
+
+	if data[0]>>4 == 6 {
+		if err := r.ip6.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+			return 0, nil, err
+		}
+		return r.ip6.NextHeader, r.ip6.Payload, nil
+	}
+
+	if err := r.ip4.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+		return 0, nil, err
+	}
+	return r.ip4.Protocol, r.ip4.Payload, nil
+}
+
+func main() {
+	opt, err := NewCmdOption(os.Args)
+	if err != nil {
+		if fe, ok := err.(*flags.Error); ok && fe.Type == flags.ErrHelp {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	r, err := newReplayer(opt)
+	if err != nil {
+		log.Fatalf("failed to parse MAC: %v", err)
+	}
+
+	f, err := os.Open(opt.ReadFile)
+	if err != nil {
+		log.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	pr, err := openReader(f)
+	if err != nil {
+		log.Fatalf("failed to read pcap file: %v", err)
+	}
+	if lt := pr.LinkType(); lt != layers.LinkTypeIPv4 && lt != layers.LinkTypeRaw {
+		log.Fatalf("unexpected link type %s, which is not written by bumcapture", lt)
+	}
+
+	var w packetWriter
+	if opt.Interface != "" {
+		ha, err := pcap.OpenLive(opt.Interface, snapshotLen, promiscuous, pcap.BlockForever)
+		if err != nil {
+			log.Fatalf("failed to open pcap handle: %v", err)
+		}
+		defer ha.Close()
+
+		w = &handleWriter{ha}
+	} else {
+		f, err := os.Create(opt.WriteFile)
+		if err != nil {
+			log.Fatalf("failed to open file: %v", err)
+		}
+		defer f.Close()
+
+		pw := pcapgo.NewWriter(f)
+		pw.WriteFileHeader(snapshotLen, layers.LinkTypeEthernet)
+		w = pw
+	}
+
+	var np, nskip uint
+	var first time.Time
+	start := time.Now()
+	for {
+		data, ci, err := pr.ReadPacketData()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("failed to read packet: %v", err)
+		}
+
+		frame, err := r.rebuild(data, r.meta(pr, ci))
+		if err != nil {
+			log.Printf("skip the packet: %v", err)
+			nskip++
+			continue
+		}
+
+		// The inter-packet gaps are scaled by the speed
+		if first.IsZero() {
+			first = ci.Timestamp
+		}
+		var offset time.Duration
+		if opt.Speed != 0 {
+			offset = time.Duration(float64(ci.Timestamp.Sub(first)) / opt.Speed)
+		}
+
+		if opt.Interface != "" {
+			time.Sleep(time.Until(start.Add(offset)))
+			ci.Timestamp = time.Now()
+		} else {
+			ci.Timestamp = first.Add(offset)
+		}
+
+		ci.CaptureLength, ci.Length = len(frame), len(frame)
+		if err := w.WritePacket(ci, frame); err != nil {
+			log.Fatalf("failed to write packet: %v", err)
+		}
+		np++
+	}
+
+	log.Printf("replayed %d packets, skipped %d packets", np, nskip)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/haccht/vplsbh/l2vpn"
+	pb "github.com/haccht/vplsbh/pkg/grpc"
+)
+
+var testFrame = []byte{
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x5e, 0x00, 0x53, 0x01, 0x08, 0x06,
+	0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01,
+}
+
+// writeCapture writes the packet on the pcapng interface recording the outer encapsulation as bumcapture does.
+func writeCapture(t *testing.T, meta *pb.Packet, ls ...gopacket.SerializableLayer) []byte {
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, ls...); err != nil {
+		t.Fatal("Failed to serialize packet:", err)
+	}
+
+	desc, err := protojson.Marshal(meta)
+	if err != nil {
+		t.Fatal("Failed to marshal record:", err)
+	}
+
+	var b bytes.Buffer
+	w, err := pcapgo.NewNgWriter(&b, layers.LinkTypeRaw)
+	if err != nil {
+		t.Fatal("Failed to write pcapng:", err)
+	}
+	id, err := w.AddInterface(pcapgo.NgInterface{Name: meta.Interface, Description: string(desc), LinkType: layers.LinkTypeRaw})
+	if err != nil {
+		t.Fatal("Failed to add interface:", err)
+	}
+
+	ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(buf.Bytes()), Length: len(buf.Bytes()), InterfaceIndex: id}
+	if err := w.WritePacket(ci, buf.Bytes()); err != nil {
+		t.Fatal("Failed to write packet:", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("Failed to flush pcapng:", err)
+	}
+	return b.Bytes()
+}
+
+// replay rebuilds the first packet of the capture and decodes its outer encapsulation.
+func replay(t *testing.T, capture []byte) (*l2vpn.Encap, []byte) {
+	opt, err := NewCmdOption([]string{"bumreplay", "-r", "test.pcapng", "-w", "replay.pcap"})
+	if err != nil {
+		t.Fatal("Failed to parse options:", err)
+	}
+	r, err := newReplayer(opt)
+	if err != nil {
+		t.Fatal("Failed to create replayer:", err)
+	}
+
+	pr, err := openReader(bytes.NewReader(capture))
+	if err != nil {
+		t.Fatal("Failed to read pcapng:", err)
+	}
+	data, ci, err := pr.ReadPacketData()
+	if err != nil {
+		t.Fatal("Failed to read packet:", err)
+	}
+
+	frame, err := r.rebuild(data, r.meta(pr, ci))
+	if err != nil {
+		t.Fatalf("The packet should be rebuilt, but was '%v'", err)
+	}
+
+	e := &l2vpn.Encap{}
+	payload, err := e.DecodeFromBytes(frame)
+	if err != nil {
+		t.Fatalf("The rebuilt frame should be decoded, but was '%v'", err)
+	}
+	return e, payload
+}
+
+func TestRebuildGRE(t *testing.T) {
+	meta := &pb.Packet{
+		Interface: "eth0",
+		Outer: &pb.Outer{
+			Encap:  pb.EncapType_GRE,
+			Vlans:  []uint32{100, 200},
+			Srcmac: "cc:15:14:64:00:00",
+			Dstmac: "cc:13:14:64:00:01",
+			Srcip:  "192.0.2.1",
+			Dstip:  "192.0.2.2",
+		},
+	}
+	capture := writeCapture(t, meta,
+		&layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolMPLSInIP, SrcIP: net.IP{192, 0, 2, 1}, DstIP: net.IPv4zero},
+		&layers.MPLS{Label: 100, StackBottom: true, TTL: 255},
+		gopacket.Payload(testFrame),
+	)
+
+	e, payload := replay(t, capture)
+	if e.Type != l2vpn.EncapTypeGRE {
+		t.Errorf("The encapsulation should be '%v', but was '%v'", l2vpn.EncapTypeGRE, e.Type)
+	}
+	if e.SrcMAC.String() != meta.Outer.Srcmac || e.DstMAC.String() != meta.Outer.Dstmac {
+		t.Errorf("The MACs should be '%s > %s', but was '%v > %v'", meta.Outer.Srcmac, meta.Outer.Dstmac, e.SrcMAC, e.DstMAC)
+	}
+	if len(e.VLANs) != 2 || e.VLANs[0] != 100 || e.VLANs[1] != 200 {
+		t.Errorf("The VLANs should be '%v', but was '%v'", meta.Outer.Vlans, e.VLANs)
+	}
+	if e.SrcIP.String() != meta.Outer.Srcip || e.DstIP.String() != meta.Outer.Dstip {
+		t.Errorf("The tunnel endpoints should be '%s > %s', but was '%v > %v'", meta.Outer.Srcip, meta.Outer.Dstip, e.SrcIP, e.DstIP)
+	}
+
+	var vpls l2vpn.VPLS
+	if err := vpls.DecodeFromBytes(payload, gopacket.NilDecodeFeedback); err != nil || vpls.Label != 100 {
+		t.Errorf("The PW label should be '100', but was '%d' (%v)", vpls.Label, err)
+	}
+}
+
+func TestRebuildVXLAN(t *testing.T) {
+	meta := &pb.Packet{
+		Vni: 5000,
+		Outer: &pb.Outer{
+			Encap:  pb.EncapType_VXLAN,
+			Srcmac: "cc:15:14:64:00:00",
+			Dstmac: "cc:13:14:64:00:01",
+			Srcip:  "2001:db8::1",
+			Dstip:  "2001:db8::2",
+		},
+	}
+	capture := writeCapture(t, meta,
+		&layers.IPv6{Version: 6, HopLimit: 64, NextHeader: layers.IPProtocolEtherIP, SrcIP: net.ParseIP("2001:db8::1"), DstIP: net.IPv6unspecified},
+		gopacket.Payload(append([]byte{0x30, 0x00}, testFrame...)),
+	)
+
+	e, payload := replay(t, capture)
+	if e.Type != l2vpn.EncapTypeVXLAN || e.VNI != meta.Vni {
+		t.Errorf("The encapsulation should be VXLAN with the VNI '%d', but was '%v' with '%d'", meta.Vni, e.Type, e.VNI)
+	}
+	if e.SrcIP.String() != meta.Outer.Srcip {
+		t.Errorf("The source VTEP should be '%s', but was '%v'", meta.Outer.Srcip, e.SrcIP)
+	}
+	if !bytes.Equal(payload, testFrame) {
+		t.Errorf("The inner frame should be restored, but was '%x'", payload)
+	}
+}
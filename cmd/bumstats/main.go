@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/google/gopacket"
@@ -54,7 +55,7 @@ func NewCmdOption(args []string) (*cmdOption, error) {
 }
 
 type packetTags struct {
-	Domain, Remote, Protocol, Type, Length string
+	Domain, Remote, Interface, Service, SVLAN, CVLAN, Protocol, Type, Length string
 }
 
 func record(db influx.Client, ch chan *packetTags, interval uint) {
@@ -76,7 +77,7 @@ func record(db influx.Client, ch chan *packetTags, interval uint) {
 
 			var n uint
 			for s, c := range count {
-				tags := map[string]string{"domain": s.Domain, "remote": s.Remote, "protocol": s.Protocol, "type": s.Type, "length": s.Length}
+				tags := map[string]string{"domain": s.Domain, "remote": s.Remote, "interface": s.Interface, "service": s.Service, "svlan": s.SVLAN, "cvlan": s.CVLAN, "protocol": s.Protocol, "type": s.Type, "length": s.Length}
 				fields := map[string]interface{}{"event": c}
 
 				pt, _ := influx.NewPoint(getEnv("INFLUXDB_SERIES", influxDBSeries), tags, fields)
@@ -172,11 +173,15 @@ func main() {
 		}
 
 		ch <- &packetTags{
-			Domain:   recv.Domain,
-			Remote:   recv.Remote,
-			Type:     typeString,
-			Length:   lengthString,
-			Protocol: eth.EthernetType.String(),
+			Domain:    recv.Domain,
+			Remote:    recv.Remote,
+			Interface: recv.Interface,
+			Service:   recv.Service.String(),
+			SVLAN:     strconv.FormatUint(uint64(recv.Svlan), 10),
+			CVLAN:     strconv.FormatUint(uint64(recv.Cvlan), 10),
+			Type:      typeString,
+			Length:    lengthString,
+			Protocol:  layers.EthernetType(recv.Ethertype).String(),
 		}
 	}
 }
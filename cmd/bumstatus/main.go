@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"google.golang.org/grpc"
+
+	pb "github.com/haccht/vplsbh/pkg/grpc"
+)
+
+type cmdOption struct {
+	Address string `short:"a" long:"addr"      description:"gRPC address to connect to" value-name:"<addr>" default:"127.0.0.1:50005"`
+	Unknown bool   `short:"u" long:"unknown"   description:"Show the labels seen without the mapping"`
+}
+
+func NewCmdOption(args []string) (*cmdOption, error) {
+	var opt cmdOption
+
+	_, err := flags.ParseArgs(&opt, args)
+	if err != nil {
+		return nil, err
+	}
+	return &opt, nil
+}
+
+func main() {
+	opt, err := NewCmdOption(os.Args)
+	if err != nil {
+		if fe, ok := err.(*flags.Error); ok && fe.Type == flags.ErrHelp {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	conn, err := grpc.Dial(opt.Address, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("failed to connect with server: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := pb.NewBumSniffServiceClient(conn)
+	if opt.Unknown {
+		printUnknown(ctx, client)
+		return
+	}
+
+	stats, err := client.Stats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		log.Fatalf("failed to get stats: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "LABEL\tCONTEXT\tDOMAIN\tREMOTE\tRECEIVED\tLOST\tOUT-OF-ORDER\tDUPLICATED")
+	for _, s := range stats.Pwstats {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d\t%d\t%d\n", s.Label, s.Context, s.Domain, s.Remote, s.Received, s.Lost, s.Outoforder, s.Duplicated)
+	}
+	w.Flush()
+
+	if len(stats.Captures) != 0 {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "INTERFACE\tRECEIVED\tDROPPED\tIF-DROPPED")
+		for _, c := range stats.Captures {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", c.Interface, c.Received, c.Dropped, c.Ifdropped)
+		}
+		w.Flush()
+	}
+
+	if len(stats.Subscribers) != 0 {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "SUBSCRIBER\tDROPPED")
+		for _, s := range stats.Subscribers {
+			fmt.Fprintf(w, "%s\t%d\n", s.Id, s.Dropped)
+		}
+		w.Flush()
+	}
+
+	if len(stats.Rejected) == 0 {
+		return
+	}
+
+	reasons := make([]string, 0, len(stats.Rejected))
+	for reason := range stats.Rejected {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "REJECTED\tCOUNT")
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "%s\t%d\n", reason, stats.Rejected[reason])
+	}
+	w.Flush()
+}
+
+// printUnknown prints the labels, or the VNIs, seen without the mapping to find the missing provisioning.
+func printUnknown(ctx context.Context, client pb.BumSniffServiceClient) {
+	reply, err := client.Unknown(ctx, &pb.UnknownRequest{})
+	if err != nil {
+		log.Fatalf("failed to get unknown labels: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "LABEL\tCONTEXT\tVNI\tFIRST-SEEN\tLAST-SEEN\tPACKETS\tOUTER-MACS")
+	for _, l := range reply.Labels {
+		firstSeen := l.Firstseen.AsTime().Local().Format(time.RFC3339)
+		lastSeen := l.Lastseen.AsTime().Local().Format(time.RFC3339)
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%d\t%s\n", l.Label, l.Context, l.Vni, firstSeen, lastSeen, l.Packets, strings.Join(l.Outermacs, ","))
+	}
+	w.Flush()
+}
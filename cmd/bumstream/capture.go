@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+)
+
+// Capture methods of the mirrored frames
+const (
+	capturePcap     = "pcap"
+	captureAFPacket = "afpacket"
+)
+
+// captureStats is the counters of the frames seen by the capture.
+// Dropped counts the frames dropped by the kernel for the lack of buffer space,
+// and IfDropped counts those dropped by the interface.
+type captureStats struct {
+	Received, Dropped, IfDropped uint64
+}
+
+func (c *captureStats) add(o captureStats) {
+	c.Received += o.Received
+	c.Dropped += o.Dropped
+	c.IfDropped += o.IfDropped
+}
+
+// packetSource reads the mirrored frames. The data is valid until the next read.
+type packetSource interface {
+	ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	CaptureStats() (captureStats, error)
+	Close()
+}
+
+// pcapSource reads the frames with libpcap.
+type pcapSource struct {
+	*pcap.Handle
+}
+
+func (h pcapSource) CaptureStats() (captureStats, error) {
+	st, err := h.Stats()
+	if err != nil {
+		return captureStats{}, err
+	}
+
+	return captureStats{
+		Received:  uint64(st.PacketsReceived),
+		Dropped:   uint64(st.PacketsDropped),
+		IfDropped: uint64(st.PacketsIfDropped),
+	}, nil
+}
+
+// source is the packet source named after the interface or the file it reads.
+// The frames of the fanout source are steered by the PW label in the kernel and decoded in place.
+type source struct {
+	packetSource
+	name   string
+	fanout bool
+}
+
+// openSources opens the packet sources on every interface and file.
+// AF_PACKET opens a socket per worker on each interface, which are joined to a fanout group.
+// The BPF filter is applied in the kernel, or in userland for the files.
+func openSources(opt *cmdOption) ([]*source, error) {
+	var sources []*source
+
+	for i, iface := range opt.Interface {
+		if opt.Capture == captureAFPacket {
+			// The fanout group is separated per interface
+			tps, err := openAFPacket(iface, int(opt.Workers), uint16(os.Getpid()+i), opt.Filter, int(opt.PWLabelIndex), opt.FAT)
+			if err != nil {
+				closeSources(sources)
+				return nil, err
+			}
+
+			for _, tp := range tps {
+				sources = append(sources, &source{packetSource: tp, name: iface, fanout: true})
+			}
+			continue
+		}
+
+		ha, err := pcap.OpenLive(iface, snapshotLen, promiscuous, pcap.BlockForever)
+		if err != nil {
+			closeSources(sources)
+			return nil, fmt.Errorf("failed to open pcap handle on %s: %v", iface, err)
+		}
+		sources = append(sources, &source{packetSource: pcapSource{ha}, name: iface})
+
+		if opt.Filter != "" {
+			if err := ha.SetBPFFilter(opt.Filter); err != nil {
+				closeSources(sources)
+				return nil, fmt.Errorf("failed to set BPF filter on %s: %v", iface, err)
+			}
+		}
+	}
+
+	for _, path := range opt.Filepath {
+		o, err := openOffline(path, opt.Speed, opt.Loop, opt.Filter)
+		if err != nil {
+			closeSources(sources)
+			return nil, fmt.Errorf("failed to open pcap file %s: %v", path, err)
+		}
+		sources = append(sources, &source{packetSource: o, name: path})
+	}
+
+	return sources, nil
+}
+
+// sourceStats sums up the capture stats of the sources per interface.
+func sourceStats(sources []*source) (map[string]captureStats, error) {
+	stats := make(map[string]captureStats)
+	for _, src := range sources {
+		st, err := src.CaptureStats()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get capture stats of %s: %v", src.name, err)
+		}
+
+		sum := stats[src.name]
+		sum.add(st)
+		stats[src.name] = sum
+	}
+
+	return stats, nil
+}
+
+func closeSources(sources []*source) {
+	for _, src := range sources {
+		src.Close()
+	}
+}
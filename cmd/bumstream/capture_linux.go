@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/afpacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// skfLLOff is SKF_LL_OFF to load the frame from the link layer header,
+// as the fanout program is run on the frame from the network header.
+const skfLLOff uint32 = 0xffe00000
+
+// tpacketPollTimeout is the interval for the reader to check if the ring is closed.
+const tpacketPollTimeout = 100 * time.Millisecond
+
+// tpacketSource reads the frames from the TPACKET_V3 ring.
+// The ring closed while read is released by the reader, as the frame read is valid until the next read.
+type tpacketSource struct {
+	*afpacket.TPacket
+	fd      int
+	ifindex int
+
+	mu                       sync.Mutex
+	reading, closing, closed bool
+}
+
+func (tp *tpacketSource) ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	for {
+		tp.mu.Lock()
+		if tp.closing {
+			tp.release()
+			tp.mu.Unlock()
+			return nil, gopacket.CaptureInfo{}, io.EOF
+		}
+		tp.reading = true
+		tp.mu.Unlock()
+
+		data, ci, err := tp.TPacket.ZeroCopyReadPacketData()
+		if err == afpacket.ErrTimeout {
+			continue
+		}
+		if err != nil {
+			tp.mu.Lock()
+			tp.reading = false
+			tp.mu.Unlock()
+		}
+		return data, ci, err
+	}
+}
+
+func (tp *tpacketSource) CaptureStats() (captureStats, error) {
+	_, st, err := tp.SocketStats()
+	if err != nil {
+		return captureStats{}, err
+	}
+
+	return captureStats{Received: uint64(st.Packets()), Dropped: uint64(st.Drops())}, nil
+}
+
+// Close releases the ring, or tells the reader to release it.
+func (tp *tpacketSource) Close() {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	tp.closing = true
+	if !tp.reading {
+		tp.release()
+	}
+}
+
+// release leaves the promiscuous mode and closes the socket, which must be called with mu held.
+func (tp *tpacketSource) release() {
+	if tp.closed {
+		return
+	}
+	tp.closed = true
+
+	mreq := unix.PacketMreq{Ifindex: int32(tp.ifindex), Type: unix.PACKET_MR_PROMISC}
+	unix.SetsockoptPacketMreq(tp.fd, unix.SOL_PACKET, unix.PACKET_DROP_MEMBERSHIP, &mreq)
+	tp.TPacket.Close()
+}
+
+// compileBPF compiles the filter expression into the instructions to attach to the socket.
+func compileBPF(expr string) ([]bpf.RawInstruction, error) {
+	insts, err := pcap.CompileBPFFilter(layers.LinkTypeEthernet, snapshotLen, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]bpf.RawInstruction, len(insts))
+	for i, inst := range insts {
+		raw[i] = bpf.RawInstruction{Op: inst.Code, Jt: inst.Jt, Jf: inst.Jf, K: inst.K}
+	}
+
+	return raw, nil
+}
+
+// openAFPacket opens the TPACKET_V3 rings on the interface, one for each of n workers.
+// The rings are joined to the fanout group id, where the kernel steers the frames by the PW label
+// so that the frames of a PW are read in order from the same ring.
+func openAFPacket(iface string, n int, id uint16, filter string, pwLabelIndex int, fat bool) ([]packetSource, error) {
+	var raw []bpf.RawInstruction
+	if filter != "" {
+		var err error
+		if raw, err = compileBPF(filter); err != nil {
+			return nil, fmt.Errorf("failed to compile BPF filter: %v", err)
+		}
+	}
+
+	var fprog unix.SockFprog
+	if n > 1 {
+		insts, err := fanoutProgram(skfLLOff, pwLabelIndex, fat)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assemble fanout program: %v", err)
+		}
+		prog, err := bpf.Assemble(insts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assemble fanout program: %v", err)
+		}
+		fprog = unix.SockFprog{Len: uint16(len(prog)), Filter: (*unix.SockFilter)(unsafe.Pointer(&prog[0]))}
+	}
+
+	ifi, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find interface %s: %v", iface, err)
+	}
+
+	var sources []packetSource
+	for i := 0; i < n; i++ {
+		tp, err := afpacket.NewTPacket(
+			afpacket.OptInterface(iface),
+			afpacket.OptTPacketVersion(afpacket.TPacketVersion3),
+			// Keep the VLAN tags of the mirror link stripped by the NIC
+			afpacket.OptAddVLANHeader(true),
+			afpacket.OptPollTimeout(tpacketPollTimeout),
+		)
+		if err != nil {
+			closePacketSources(sources)
+			return nil, fmt.Errorf("failed to open AF_PACKET socket on %s: %v", iface, err)
+		}
+
+		// The socket is not exposed by afpacket
+		fd := int(reflect.ValueOf(tp).Elem().FieldByName("fd").Int())
+
+		mreq := unix.PacketMreq{Ifindex: int32(ifi.Index), Type: unix.PACKET_MR_PROMISC}
+		if err := unix.SetsockoptPacketMreq(fd, unix.SOL_PACKET, unix.PACKET_ADD_MEMBERSHIP, &mreq); err != nil {
+			tp.Close()
+			closePacketSources(sources)
+			return nil, fmt.Errorf("failed to enter promiscuous mode on %s: %v", iface, err)
+		}
+		sources = append(sources, &tpacketSource{TPacket: tp, fd: fd, ifindex: ifi.Index})
+
+		if raw != nil {
+			if err := tp.SetBPF(raw); err != nil {
+				closePacketSources(sources)
+				return nil, fmt.Errorf("failed to set BPF filter on %s: %v", iface, err)
+			}
+		}
+
+		if n > 1 {
+			if err := tp.SetFanout(afpacket.FanoutCBPF, id); err != nil {
+				closePacketSources(sources)
+				return nil, fmt.Errorf("failed to join fanout group on %s: %v", iface, err)
+			}
+			if err := unix.SetsockoptSockFprog(fd, unix.SOL_PACKET, unix.PACKET_FANOUT_DATA, &fprog); err != nil {
+				closePacketSources(sources)
+				return nil, fmt.Errorf("failed to set fanout program on %s: %v", iface, err)
+			}
+		}
+	}
+
+	return sources, nil
+}
+
+func closePacketSources(sources []packetSource) {
+	for _, src := range sources {
+		src.Close()
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package main
+
+import (
+	"fmt"
+)
+
+func openAFPacket(iface string, n int, id uint16, filter string, pwLabelIndex int, fat bool) ([]packetSource, error) {
+	return nil, fmt.Errorf("AF_PACKET is not supported on this platform")
+}
@@ -0,0 +1,719 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/haccht/vplsbh/l2vpn"
+	pb "github.com/haccht/vplsbh/pkg/grpc"
+	"github.com/haccht/vplsbh/resolver"
+)
+
+var errUnknownLabel = errors.New("label is not found")
+
+// rejectReason is the reason why the mirrored frame was not published.
+type rejectReason int
+
+const (
+	rejectNoMPLS rejectReason = iota
+	rejectMissingControlWord
+	rejectTruncated
+	rejectMalformed
+	rejectNoPWLabel
+	rejectUnknownLabel
+	numRejectReasons
+)
+
+var rejectReasonNames = [numRejectReasons]string{
+	rejectNoMPLS:             "NoMPLS",
+	rejectMissingControlWord: "MissingControlWord",
+	rejectTruncated:          "Truncated",
+	rejectMalformed:          "Malformed",
+	rejectNoPWLabel:          "NoPWLabel",
+	rejectUnknownLabel:       "UnknownLabel",
+}
+
+func (r rejectReason) String() string {
+	return rejectReasonNames[r]
+}
+
+// rejectReasonOf classifies the error returned by the decoder.
+func rejectReasonOf(err error) rejectReason {
+	switch {
+	case errors.Is(err, l2vpn.ErrNoMPLS):
+		return rejectNoMPLS
+	case errors.Is(err, l2vpn.ErrMissingControlWord):
+		return rejectMissingControlWord
+	case errors.Is(err, l2vpn.ErrTruncated):
+		return rejectTruncated
+	case errors.Is(err, l2vpn.ErrNoPWLabel), errors.Is(err, l2vpn.ErrNoFlowLabel):
+		return rejectNoPWLabel
+	case errors.Is(err, errUnknownLabel):
+		return rejectUnknownLabel
+	default:
+		return rejectMalformed
+	}
+}
+
+// slabSize is the size of the buffer which the packet data is carved out of.
+const slabSize = 1 << 20
+
+// slab carves the copies of the data out of a large buffer to allocate less often.
+// The buffer is released by GC when all the packets referring to it are released.
+type slab struct {
+	buf []byte
+}
+
+func (s *slab) copy(data []byte) []byte {
+	if len(data) > cap(s.buf)-len(s.buf) {
+		size := slabSize
+		if len(data) > size {
+			size = len(data)
+		}
+		s.buf = make([]byte, 0, size)
+	}
+
+	n := len(s.buf)
+	s.buf = append(s.buf, data...)
+	return s.buf[n:len(s.buf):len(s.buf)]
+}
+
+// maxLabels is the depth of the label stack allocated together with the packet.
+const maxLabels = 8
+
+// packetAlloc allocates the packet together with its fields at once.
+type packetAlloc struct {
+	packet    pb.Packet
+	timestamp timestamppb.Timestamp
+	outer     pb.Outer
+	vlans     [2]uint32
+	labels    [maxLabels]pb.LabelStackEntry
+	labelPtrs [maxLabels]*pb.LabelStackEntry
+}
+
+// newPacket returns the packet with its timestamp and outer encapsulation.
+func (d *decoder) newPacket(ci gopacket.CaptureInfo) (*pb.Packet, *packetAlloc) {
+	a := &packetAlloc{}
+
+	a.timestamp.Seconds = ci.Timestamp.Unix()
+	a.timestamp.Nanos = int32(ci.Timestamp.Nanosecond())
+	a.packet.Timestamp = &a.timestamp
+
+	d.fillOuter(&a.outer, a.vlans[:0])
+	a.packet.Outer = &a.outer
+
+	return &a.packet, a
+}
+
+// setLabels fills the label stack of the packet.
+func (a *packetAlloc) setLabels(stack []l2vpn.LabelStackEntry) {
+	labels := a.labelPtrs[:0]
+	for i, e := range stack {
+		var l *pb.LabelStackEntry
+		if i < maxLabels {
+			l = &a.labels[i]
+		} else {
+			l = &pb.LabelStackEntry{}
+		}
+
+		l.Label = e.Label
+		l.Tc = uint32(e.TrafficClass)
+		l.Bottom = e.StackBottom
+		l.Ttl = uint32(e.TTL)
+		l.Kind = pb.LabelKind(e.Kind)
+		labels = append(labels, l)
+	}
+
+	a.packet.Labels = labels
+}
+
+// decoder decodes the mirrored frames into packets, reusing its layers and parsers frame by frame.
+type decoder struct {
+	s *streamer
+
+	// copyData tells that the frame is overwritten by the next read, so that the packet data must be copied.
+	copyData bool
+	slab     slab
+
+	encap l2vpn.Encap
+	vpls  l2vpn.VPLS
+	pwmcw l2vpn.PWMCW
+	pwach l2vpn.PWACH
+	eth   layers.Ethernet
+	dot1q layers.Dot1Q
+	pbb   l2vpn.PBB
+
+	vplsParser  *gopacket.DecodingLayerParser
+	pwachParser *gopacket.DecodingLayerParser
+	pwmcwParser *gopacket.DecodingLayerParser
+	ethParser   *gopacket.DecodingLayerParser
+
+	decoded []gopacket.LayerType
+
+	// The outer addresses formatted last, which rarely change on the mirror link.
+	srcMAC, dstMAC addrCache
+	srcIP, dstIP   addrCache
+
+	// The state of the PWs and the unknown labels are kept per decoder, as the dispatcher pins a PW to a worker.
+	// pwLock is taken by Stats as well as by the decoder.
+	pwLock   sync.Mutex
+	pws      map[labelKey]*pwState
+	pruned   time.Time
+	unknowns *unknownRegistry
+}
+
+// newParser returns the parser which stops without an error at the layer not given.
+func newParser(first gopacket.LayerType, dl ...gopacket.DecodingLayer) *gopacket.DecodingLayerParser {
+	parser := gopacket.NewDecodingLayerParser(first, dl...)
+	parser.IgnoreUnsupported = true
+	return parser
+}
+
+func (s *streamer) newDecoder(copyData bool) *decoder {
+	d := &decoder{
+		s:        s,
+		copyData: copyData,
+		// The whole label stack is decoded and the PW label is picked at the configured position.
+		// FAT flow labels and entropy labels are never taken as the PW label.
+		vpls:    l2vpn.VPLS{PWLabelIndex: s.pwLabelIndex, FAT: s.fat, DetectFAT: true},
+		decoded: make([]gopacket.LayerType, 0, 3),
+
+		pws:      make(map[labelKey]*pwState),
+		unknowns: newUnknownRegistry(),
+	}
+
+	d.vplsParser = newParser(layers.LayerTypeMPLS, &d.vpls)
+	d.pwachParser = newParser(l2vpn.LayerTypePWACH, &d.pwach)
+	d.pwmcwParser = newParser(l2vpn.LayerTypePWMCW, &d.pwmcw, &d.eth)
+	d.ethParser = newParser(layers.LayerTypeEthernet, &d.eth)
+
+	s.Lock()
+	s.decoders = append(s.decoders, d)
+	s.Unlock()
+
+	return d
+}
+
+// keyer hashes the PW of the frame to dispatch it to the worker, which keeps the state of the PW.
+// The PW is keyed by the label in its context as the decoder does, or by the VNI of VXLAN.
+// The ESI label and the FAT flow label are told by the label store as the decoder does.
+type keyer struct {
+	s *streamer
+
+	encap          l2vpn.Encap
+	vpls           l2vpn.VPLS
+	srcMAC, dstMAC addrCache
+}
+
+func (s *streamer) newKeyer() *keyer {
+	return &keyer{s: s, vpls: l2vpn.VPLS{PWLabelIndex: s.pwLabelIndex, FAT: s.fat, DetectFAT: true}}
+}
+
+// key returns 0 for the frame which can not be decoded, which is to be rejected by the worker.
+func (k *keyer) key(data []byte, iface string) uint32 {
+	payload, err := k.encap.DecodeFromBytes(data)
+	if err != nil {
+		return 0
+	}
+
+	if k.encap.Type == l2vpn.EncapTypeVXLAN {
+		return k.encap.VNI
+	}
+
+	if err := k.vpls.DecodeFromBytes(payload, gopacket.NilDecodeFeedback); err != nil {
+		return 0
+	}
+
+	// The label store is only peeked not to block the dispatch on the lookup,
+	// and the label not cached yet is keyed as it is
+	context := k.s.contextOf(k.s.cache.Peek, &k.encap, iface, &k.srcMAC, &k.dstMAC)
+	k.s.pickPW(k.s.cache.Peek, &k.vpls, context)
+	return hashKey(context, k.vpls.Label)
+}
+
+// hashKey returns the FNV-1a hash of the label in the context.
+func hashKey(context string, label uint32) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(context); i++ {
+		h ^= uint32(context[i])
+		h *= 16777619
+	}
+	for i := 0; i < 4; i++ {
+		h ^= (label >> (8 * i)) & 0xff
+		h *= 16777619
+	}
+	return h
+}
+
+// packetData returns the data of the packet, which is copied only if the frame is to be overwritten.
+func (d *decoder) packetData(data []byte) []byte {
+	if d.copyData {
+		return d.slab.copy(data)
+	}
+	return data[:len(data):len(data)]
+}
+
+// decodeBackbone decodes the B-TAG and the I-TAG following the backbone MACs of PBB-VPLS.
+func (d *decoder) decodeBackbone() *pb.Backbone {
+	df := gopacket.NilDecodeFeedback
+	etype, data := d.eth.EthernetType, d.eth.Payload
+
+	var bvid uint16
+	if d.eth.NextLayerType() == layers.LayerTypeDot1Q {
+		if err := d.dot1q.DecodeFromBytes(data, df); err != nil {
+			return nil
+		}
+		bvid = d.dot1q.VLANIdentifier
+		etype, data = d.dot1q.Type, d.dot1q.Payload
+	}
+
+	// The I-TAG is told by its EtherType, which is not registered to gopacket
+	if etype != l2vpn.EthernetTypePBB || d.pbb.DecodeFromBytes(data, df) != nil {
+		return nil
+	}
+
+	return &pb.Backbone{
+		Srcmac: d.eth.SrcMAC.String(),
+		Dstmac: d.eth.DstMAC.String(),
+		Bvid:   uint32(bvid),
+		Isid:   d.pbb.ISID,
+	}
+}
+
+// addrCache keeps the address formatted last to reuse the string while the address is not changed.
+type addrCache struct {
+	addr []byte
+	str  string
+}
+
+func (c *addrCache) mac(mac net.HardwareAddr) string {
+	if c.str == "" || !bytes.Equal(mac, c.addr) {
+		c.addr = append(c.addr[:0], mac...)
+		c.str = mac.String()
+	}
+	return c.str
+}
+
+func (c *addrCache) ip(ip net.IP) string {
+	if c.str == "" || !bytes.Equal(ip, c.addr) {
+		c.addr = append(c.addr[:0], ip...)
+		c.str = ip.String()
+	}
+	return c.str
+}
+
+// labelContext returns the context of the PE which assigned the labels of the frame,
+// which is empty if the labels are shared by all the PEs.
+func (d *decoder) labelContext(iface string) string {
+	return d.s.contextOf(d.s.cache.Get, &d.encap, iface, &d.srcMAC, &d.dstMAC)
+}
+
+// contextOf returns the context of the labels of the frame decoded into e, formatting the MACs with the caches.
+// The neighbor is resolved with get, which is Get of the label store or Peek not to look it up.
+func (s *streamer) contextOf(get func(interface{}) (interface{}, bool), e *l2vpn.Encap, iface string, srcMAC, dstMAC *addrCache) string {
+	switch s.labelContext {
+	case labelContextInterface:
+		return iface
+	case labelContextSrcMAC:
+		return srcMAC.mac(e.SrcMAC)
+	case labelContextDstMAC:
+		return dstMAC.mac(e.DstMAC)
+	case labelContextNeighbor:
+		// The LDP neighbor is the PE which the frame is sent to, resolved by its MAC
+		if v, ok := get(macKey(dstMAC.mac(e.DstMAC))); ok {
+			return v.(*resolver.Info).PeerID
+		}
+	}
+	return ""
+}
+
+// labelInfo returns the attributes of the label in the context, or of the label shared by all the PEs,
+// with get, which is Get of the label store or Peek not to look it up.
+func (s *streamer) labelInfo(get func(interface{}) (interface{}, bool), context string, label uint32) (*resolver.Info, bool) {
+	if context != "" {
+		if v, ok := get(labelKey{context, label}); ok {
+			return v.(*resolver.Info), true
+		}
+	}
+	if v, ok := get(label); ok {
+		return v.(*resolver.Info), true
+	}
+
+	return nil, false
+}
+
+// resolvePW returns the attributes of the PW label picked in v with get.
+// The bottom label of EVPN BUM traffic may be the ESI label under the EVI label, which is returned with the ESI.
+func (s *streamer) resolvePW(get func(interface{}) (interface{}, bool), v *l2vpn.VPLS, context string) (*resolver.Info, string, bool, error) {
+	t, ok := s.labelInfo(get, context, v.Label)
+	if !ok || t.Type != labelTypeESI {
+		return t, "", ok, nil
+	}
+
+	esi := t.ESI
+	if !v.SplitESILabel() {
+		return nil, "", false, &l2vpn.DecodeError{Layer: "VPLS", Err: l2vpn.ErrNoPWLabel}
+	}
+
+	t, ok = s.labelInfo(get, context, v.Label)
+	return t, esi, ok, nil
+}
+
+// pickPW resolves the PW label picked in v with get, which is Get of the label store or Peek not to look it up.
+// The FAT attribute of the PW overrides the flow label assumed by --fat or told by its TTL,
+// and the PW label is picked again the other way if the label is not found, to find the label with the FAT attribute.
+// The PW label picked first is kept unless the label picked again is found.
+func (s *streamer) pickPW(get func(interface{}) (interface{}, bool), v *l2vpn.VPLS, context string) (*resolver.Info, string, bool, error) {
+	t, esi, ok, err := s.resolvePW(get, v, context)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	flow, split := v.FlowLabel != nil, v.ESILabel != nil
+
+	retry := true
+	if ok {
+		fat, given := fatAttr(t)
+		retry = given && fat != flow
+	}
+	if !retry {
+		return t, esi, ok, nil
+	}
+
+	if v.PickPW(!flow) == nil {
+		nt, nesi, nok, err := s.resolvePW(get, v, context)
+		if err == nil && nok {
+			fat, given := fatAttr(nt)
+			if given && fat == !flow || ok && !given {
+				return nt, nesi, true, nil
+			}
+		}
+	}
+
+	v.PickPW(flow)
+	if split {
+		v.SplitESILabel()
+	}
+	return t, esi, ok, nil
+}
+
+// fatAttr returns the FAT attribute of the label, and whether it is given.
+func fatAttr(t *resolver.Info) (fat, given bool) {
+	fat, err := strconv.ParseBool(t.FAT)
+	return fat, err == nil
+}
+
+// unknownLabel records the label in the context not found by the label store.
+func (d *decoder) unknownLabel(context string, label uint32, ci gopacket.CaptureInfo) (*resolver.Info, error) {
+	if context == "" {
+		return d.unknown(label, ci)
+	}
+	return d.unknown(labelKey{context, label}, ci)
+}
+
+// lookup returns the attributes of the label or the VNI keyed by k.
+func (d *decoder) lookup(k interface{}, ci gopacket.CaptureInfo) (*resolver.Info, error) {
+	if v, ok := d.s.cache.Get(k); ok {
+		return v.(*resolver.Info), nil
+	}
+
+	return d.unknown(k, ci)
+}
+
+// unknown records the key not found in the registry, which is published with the unknown marker if enabled.
+func (d *decoder) unknown(k interface{}, ci gopacket.CaptureInfo) (*resolver.Info, error) {
+	s := d.s
+	d.unknowns.record(k, ci.Timestamp, d.encap.SrcMAC)
+	if !s.publishUnknown {
+		return nil, errUnknownLabel
+	}
+
+	return unknownLabelInfo, nil
+}
+
+// pwState returns the state of the PW. The caller must hold pwLock.
+// The state is not kept for the new PW while the decoder has maxPWStates PWs seen recently.
+func (d *decoder) pwState(k labelKey) *pwState {
+	now := time.Now()
+
+	pw, ok := d.pws[k]
+	if !ok {
+		// The stale PWs are pruned at most once a minute when the states are full
+		if len(d.pws) >= maxPWStates && now.Sub(d.pruned) > time.Minute {
+			d.prunePWs(now)
+		}
+
+		pw = &pwState{}
+		if len(d.pws) < maxPWStates {
+			d.pws[k] = pw
+		}
+	}
+
+	pw.updated = now
+	return pw
+}
+
+// prunePWs forgets the PWs not seen any more. The caller must hold pwLock.
+func (d *decoder) prunePWs(now time.Time) {
+	for k, pw := range d.pws {
+		if now.Sub(pw.updated) > pwStateTTL {
+			delete(d.pws, k)
+		}
+	}
+	d.pruned = now
+}
+
+// hasControlWord tells whether the PW payload starts with the control word.
+// The ControlWord attribute of the label is trusted if given, and the control word is detected from the payload otherwise.
+// The detection starts over when the label is resolved to other attributes, as the PW may have been signaled again.
+func (d *decoder) hasControlWord(k labelKey, t *resolver.Info, payload []byte) bool {
+	if cw, err := strconv.ParseBool(t.ControlWord); err == nil {
+		return cw
+	}
+
+	// The state is not kept for the label not found, which may be junk
+	if t == unknownLabelInfo {
+		var det l2vpn.ControlWordDetector
+		return det.Detect(payload)
+	}
+
+	d.pwLock.Lock()
+	defer d.pwLock.Unlock()
+
+	pw := d.pwState(k)
+	if pw.info != *t {
+		pw.info = *t
+		pw.ControlWordDetector.Reset()
+	}
+	return pw.Detect(payload)
+}
+
+func (d *decoder) updateSequence(k labelKey, domain, remote string, seq uint16) {
+	d.pwLock.Lock()
+	defer d.pwLock.Unlock()
+
+	// The PW may have been moved to another domain or remote
+	pw := d.pwState(k)
+	pw.Domain, pw.Remote = domain, remote
+	pw.Update(seq)
+}
+
+// pwStats returns the sequence statistics of the PWs received by the decoder.
+func (d *decoder) pwStats() []*pb.PWStats {
+	d.pwLock.Lock()
+	defer d.pwLock.Unlock()
+
+	d.prunePWs(time.Now())
+
+	var stats []*pb.PWStats
+	for k, pw := range d.pws {
+		if pw.Received == 0 {
+			continue
+		}
+
+		stats = append(stats, &pb.PWStats{
+			Label:      k.label,
+			Context:    k.context,
+			Domain:     pw.Domain,
+			Remote:     pw.Remote,
+			Received:   pw.Received,
+			Lost:       pw.Lost,
+			Outoforder: pw.OutOfOrder,
+			Duplicated: pw.Duplicated,
+		})
+	}
+	return stats
+}
+
+func (d *decoder) decode(data []byte, ci gopacket.CaptureInfo, iface string) (*pb.Packet, error) {
+	s := d.s
+
+	// Decode the outer encapsulation in front of the MPLS label stack
+	payload, err := d.encap.DecodeFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// VXLAN carries the BUM frame without MPLS
+	if d.encap.Type == l2vpn.EncapTypeVXLAN {
+		return d.decodeVXLAN(payload, ci)
+	}
+
+	// Decode the VPLS layer
+	if err := d.vplsParser.DecodeLayers(payload, &d.decoded); err != nil {
+		return nil, err
+	}
+
+	// The labels are assigned per PE, which is told by the context
+	context := d.labelContext(iface)
+
+	t, esi, ok, err := s.pickPW(s.cache.Get, &d.vpls, context)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if t, err = d.unknownLabel(context, d.vpls.Label, ci); err != nil {
+			return nil, err
+		}
+	}
+
+	service := pb.ServiceType_VPLS
+	if t.Type == labelTypeEVPN {
+		service = pb.ServiceType_EVPN
+	}
+
+	// Decode the inner Ethernet layer with or without the control word,
+	// or the PW Associated Channel carrying OAM messages.
+	// The frame is taken as is from the Ethernet header to the end.
+	var rawData []byte
+	var channel uint32
+
+	kind := pb.PacketKind_DATA
+	pw := labelKey{context, d.vpls.Label}
+	cw := d.hasControlWord(pw, t, d.vpls.Payload)
+
+	switch {
+	case cw && l2vpn.IsPWACH(d.vpls.Payload):
+		d.pwmcw = l2vpn.PWMCW{}
+
+		if err := d.pwachParser.DecodeLayers(d.vpls.Payload, &d.decoded); err != nil {
+			return nil, err
+		}
+
+		kind = pb.PacketKind_OAM
+		channel = uint32(d.pwach.ChannelType)
+		rawData = d.vpls.Payload
+	case cw:
+		if err := d.pwmcwParser.DecodeLayers(d.vpls.Payload, &d.decoded); err != nil {
+			return nil, err
+		}
+
+		rawData = d.pwmcw.Payload
+	default:
+		d.pwmcw = l2vpn.PWMCW{}
+
+		if err := d.ethParser.DecodeLayers(d.vpls.Payload, &d.decoded); err != nil {
+			return nil, err
+		}
+
+		rawData = d.vpls.Payload
+	}
+
+	// PBB-VPLS carries the customer frame in the backbone frame, whose domain is resolved per I-SID
+	domain := t.Domain
+
+	var backbone *pb.Backbone
+	if kind == pb.PacketKind_DATA {
+		if backbone = d.decodeBackbone(); backbone != nil {
+			if v, ok := s.cache.Get(isidKey(d.pbb.ISID)); ok && v.(*resolver.Info).Domain != "" {
+				domain = v.(*resolver.Info).Domain
+			}
+
+			rawData = d.pbb.Payload
+		}
+	}
+
+	if kind == pb.PacketKind_DATA && t != unknownLabelInfo {
+		d.updateSequence(pw, t.Domain, t.Remote, d.pwmcw.SequenceNumber)
+	}
+
+	p, a := d.newPacket(ci)
+	a.setLabels(d.vpls.Stack)
+
+	p.Data = d.packetData(rawData)
+	p.Label = d.vpls.Label
+	p.Domain = domain
+	p.Remote = t.Remote
+	p.Peerid = t.PeerID
+	p.Sequence = uint32(d.pwmcw.SequenceNumber)
+	p.Controlword = cw
+	p.Kind = kind
+	p.Channel = channel
+	p.Service = service
+	p.Esi = esi
+	p.Backbone = backbone
+
+	if kind == pb.PacketKind_DATA {
+		setVLANs(p)
+	}
+
+	return p, nil
+}
+
+// decodeVXLAN resolves the domain by the VNI and the remote by the source VTEP instead of the label.
+func (d *decoder) decodeVXLAN(payload []byte, ci gopacket.CaptureInfo) (*pb.Packet, error) {
+	s, e := d.s, &d.encap
+
+	if err := d.eth.DecodeFromBytes(payload, gopacket.NilDecodeFeedback); err != nil {
+		return nil, &l2vpn.DecodeError{Layer: "Ethernet", Err: l2vpn.ErrTruncated}
+	}
+
+	t, err := d.lookup(vniKey(e.VNI), ci)
+	if err != nil {
+		return nil, err
+	}
+
+	// The VTEP is identified by its address unless it has a name resolved
+	vtep := d.srcIP.ip(e.SrcIP)
+	remote, peerID := vtep, vtep
+	if v, ok := s.cache.Get(vtepKey(vtep)); ok {
+		if r := v.(*resolver.Info); r.Remote != "" {
+			remote = r.Remote
+		}
+	}
+
+	p, _ := d.newPacket(ci)
+	p.Data = d.packetData(payload)
+	p.Vni = e.VNI
+	p.Domain = t.Domain
+	p.Remote = remote
+	p.Peerid = peerID
+	p.Service = pb.ServiceType_EVPN
+
+	setVLANs(p)
+	return p, nil
+}
+
+// setVLANs fills the VLANs and the EtherType of the customer frame in the packet.
+func setVLANs(p *pb.Packet) {
+	var v l2vpn.VLANTags
+	if err := v.DecodeFromBytes(p.Data); err != nil {
+		return
+	}
+
+	p.Svlan = uint32(v.SVLAN)
+	p.Cvlan = uint32(v.CVLAN)
+	p.Ethertype = uint32(v.EthernetType)
+}
+
+// fillOuter fills the outer encapsulation, appending the VLANs to vlans.
+func (d *decoder) fillOuter(o *pb.Outer, vlans []uint32) {
+	e := &d.encap
+
+	o.Encap = pb.EncapType(e.Type)
+	o.Srcmac = d.srcMAC.mac(e.SrcMAC)
+	o.Dstmac = d.dstMAC.mac(e.DstMAC)
+
+	o.Erspanversion = uint32(e.ERSPANVersion)
+	o.Sessionid = uint32(e.SessionID)
+	o.Timestamp = e.Timestamp
+	o.Granularity = uint32(e.Granularity)
+
+	for _, vid := range e.VLANs {
+		vlans = append(vlans, uint32(vid))
+	}
+	o.Vlans = vlans
+
+	if e.SrcIP != nil && e.DstIP != nil {
+		o.Srcip = d.srcIP.ip(e.SrcIP)
+		o.Dstip = d.dstIP.ip(e.DstIP)
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	"github.com/haccht/vplsbh/l2vpn"
+	"github.com/haccht/vplsbh/resolver"
+)
+
+// testResolver resolves the keys with the static mappings.
+type testResolver map[string]*resolver.Info
+
+func (r testResolver) Resolve(key string) (*resolver.Info, error) {
+	t, ok := r[key]
+	if !ok {
+		return nil, resolver.ErrNotFound
+	}
+	return t, nil
+}
+
+var testInfos = testResolver{
+	"label:100":      {Domain: "bd-100", Remote: "pe1", PeerID: "192.0.2.1", ControlWord: "false"},
+	"label:200":      {Domain: "bd-200", Remote: "pe2", PeerID: "192.0.2.2", ControlWord: "true"},
+	"label:300":      {Domain: "bd-300", Remote: "pe3", PeerID: "192.0.2.3"},
+	"label:eth0:400": {Type: "EVPN", Domain: "evi-400", Remote: "pe4", ControlWord: "false"},
+	"label:eth0:401": {Type: "ESI", ESI: "00:11:22:33:44:55:66:77:88:99"},
+	"label:500":      {Domain: "bd-500", Remote: "pe5", FAT: "true", ControlWord: "false"},
+	"label:600":      {Domain: "bd-600", Remote: "pe6", FAT: "false", ControlWord: "false"},
+	"vni:5000":       {Domain: "bd-5000"},
+	"isid:10000":     {Domain: "bd-10000"},
+}
+
+var (
+	testOuterSrcMAC = net.HardwareAddr{0xcc, 0x15, 0x14, 0x64, 0x00, 0x00}
+	testOuterDstMAC = net.HardwareAddr{0xcc, 0x13, 0x14, 0x64, 0x00, 0x01}
+	testInnerSrcMAC = net.HardwareAddr{0x00, 0x00, 0x5e, 0x00, 0x53, 0x01}
+	testBroadcast   = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+)
+
+func serializeFrame(tb testing.TB, ls ...gopacket.SerializableLayer) []byte {
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true}
+	if err := gopacket.SerializeLayers(buf, opts, ls...); err != nil {
+		tb.Fatal("Failed to serialize frame:", err)
+	}
+	return buf.Bytes()
+}
+
+// testFrames returns the mirrored BUM frames of EoMPLS with and without the control word, and of VXLAN.
+func testFrames(tb testing.TB) map[string][]byte {
+	inner := func() []gopacket.SerializableLayer {
+		return []gopacket.SerializableLayer{
+			&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast, EthernetType: layers.EthernetTypeARP},
+			gopacket.Payload(make([]byte, 46)),
+		}
+	}
+	outer := &layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: layers.EthernetTypeMPLSUnicast}
+
+	return map[string][]byte{
+		"EoMPLS": serializeFrame(tb, append([]gopacket.SerializableLayer{
+			outer,
+			&layers.MPLS{Label: 100, StackBottom: true, TTL: 255},
+		}, inner()...)...),
+		"EoMPLSCW": serializeFrame(tb, append([]gopacket.SerializableLayer{
+			outer,
+			&layers.MPLS{Label: 200, StackBottom: true, TTL: 255},
+			&l2vpn.PWMCW{SequenceNumber: 1},
+		}, inner()...)...),
+		"VXLAN": serializeFrame(tb, append([]gopacket.SerializableLayer{
+			&layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: layers.EthernetTypeIPv4},
+			&layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolUDP, SrcIP: net.IP{192, 0, 2, 1}, DstIP: net.IP{192, 0, 2, 2}},
+			&layers.UDP{SrcPort: 49152, DstPort: l2vpn.UDPPortVXLAN},
+			&layers.VXLAN{ValidIDFlag: true, VNI: 5000},
+		}, inner()...)...),
+	}
+}
+
+func newTestStreamer(tb testing.TB, args ...string) *streamer {
+	opt, err := NewCmdOption(append([]string{"bumstream", "-r", "test.pcap", "--resolver", "test.yaml"}, args...))
+	if err != nil {
+		tb.Fatal("Failed to parse options:", err)
+	}
+	return NewStreamer(opt, testInfos)
+}
+
+func TestDecode(t *testing.T) {
+	s := newTestStreamer(t)
+	d := s.newDecoder(false)
+
+	ci := gopacket.CaptureInfo{Timestamp: time.Now()}
+	for name, data := range testFrames(t) {
+		ci.CaptureLength, ci.Length = len(data), len(data)
+
+		p, err := d.decode(data, ci, "eth0")
+		if err != nil {
+			t.Fatalf("The %s frame should be decoded, but was '%v'", name, err)
+		}
+
+		if p.Outer.Srcmac != testOuterSrcMAC.String() || p.Outer.Dstmac != testOuterDstMAC.String() {
+			t.Errorf("The outer MACs of the %s frame should be '%v > %v', but was '%s > %s'", name, testOuterSrcMAC, testOuterDstMAC, p.Outer.Srcmac, p.Outer.Dstmac)
+		}
+		if len(p.Data) != 60 {
+			t.Errorf("The %s frame should carry the 60-byte customer frame, but was '%d'", name, len(p.Data))
+		}
+	}
+}
+
+func TestDecodePBB(t *testing.T) {
+	s := newTestStreamer(t)
+	d := s.newDecoder(false)
+
+	data := serializeFrame(t,
+		&layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: layers.EthernetTypeMPLSUnicast},
+		&layers.MPLS{Label: 100, StackBottom: true, TTL: 255},
+		&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast, EthernetType: layers.EthernetTypeDot1Q},
+		&layers.Dot1Q{VLANIdentifier: 10, Type: l2vpn.EthernetTypePBB},
+		&l2vpn.PBB{ISID: 10000, SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast},
+		&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast, EthernetType: layers.EthernetTypeARP},
+		gopacket.Payload(make([]byte, 46)),
+	)
+	ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+
+	// The I-TAG is decoded without registering its EtherType to gopacket
+	p, err := d.decode(data, ci, "eth0")
+	if err != nil {
+		t.Fatalf("The PBB frame should be decoded, but was '%v'", err)
+	}
+	if p.Backbone == nil || p.Backbone.Isid != 10000 || p.Backbone.Bvid != 10 {
+		t.Fatalf("The backbone of the PBB frame should be the I-SID '10000' in the B-VID '10', but was '%v'", p.Backbone)
+	}
+	if p.Domain != "bd-10000" {
+		t.Errorf("The domain of the PBB frame should be 'bd-10000', but was '%s'", p.Domain)
+	}
+	if len(p.Data) != 60 {
+		t.Errorf("The PBB frame should carry the 60-byte customer frame, but was '%d'", len(p.Data))
+	}
+}
+
+func TestDecodeFAT(t *testing.T) {
+	frame := func(labels ...*layers.MPLS) []byte {
+		ls := []gopacket.SerializableLayer{
+			&layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: layers.EthernetTypeMPLSUnicast},
+		}
+		for i, l := range labels {
+			l.StackBottom = i == len(labels)-1
+			ls = append(ls, l)
+		}
+		ls = append(ls,
+			&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast, EthernetType: layers.EthernetTypeARP},
+			gopacket.Payload(make([]byte, 46)),
+		)
+		return serializeFrame(t, ls...)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		data     []byte
+		expected uint32
+	}{
+		{"FlowLabelTTL", nil, frame(&layers.MPLS{Label: 100, TTL: 255}, &layers.MPLS{Label: 12345, TTL: 0}), 100},
+		{"FATAttribute", nil, frame(&layers.MPLS{Label: 500, TTL: 255}, &layers.MPLS{Label: 12345, TTL: 255}), 500},
+		{"FATDefault", []string{"--fat"}, frame(&layers.MPLS{Label: 100, TTL: 255}, &layers.MPLS{Label: 12345, TTL: 255}), 100},
+		{"NoFATAttribute", []string{"--fat"}, frame(&layers.MPLS{Label: 16000, TTL: 255}, &layers.MPLS{Label: 600, TTL: 255}), 600},
+	}
+
+	for _, tt := range tests {
+		s := newTestStreamer(t, tt.args...)
+		d := s.newDecoder(false)
+		k := s.newKeyer()
+
+		data := tt.data
+		ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+
+		p, err := d.decode(data, ci, "eth0")
+		if err != nil {
+			t.Fatalf("The %s frame should be decoded, but was '%v'", tt.name, err)
+		}
+		if p.Label != tt.expected {
+			t.Errorf("The PW label of the %s frame should be '%d', but was '%d'", tt.name, tt.expected, p.Label)
+		}
+		if k.key(data, "eth0") != hashKey("", tt.expected) {
+			t.Errorf("The %s frame should be dispatched by the PW label '%d'", tt.name, tt.expected)
+		}
+	}
+}
+
+func TestResetControlWords(t *testing.T) {
+	s := newTestStreamer(t)
+	d := s.newDecoder(false)
+
+	// IPv4 multicast frame without the control word
+	data := serializeFrame(t,
+		&layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: layers.EthernetTypeMPLSUnicast},
+		&layers.MPLS{Label: 300, StackBottom: true, TTL: 255},
+		&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0x05}, EthernetType: layers.EthernetTypeIPv4},
+		gopacket.Payload(make([]byte, 46)),
+	)
+	ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+
+	p, err := d.decode(data, ci, "eth0")
+	if err != nil {
+		t.Fatalf("The frame should be decoded, but was '%v'", err)
+	}
+	if p.Controlword {
+		t.Error("The frame to the multicast MAC should not have the control word")
+	}
+
+	pw := d.pws[labelKey{"", 300}]
+	if !pw.Absent() {
+		t.Error("The PW should be learned to have no control word")
+	}
+
+	s.resetControlWords(uint32(300))
+	if pw.Absent() {
+		t.Error("The control word learned for the PW should be forgotten when its label is invalidated")
+	}
+}
+
+func TestPWStates(t *testing.T) {
+	s := newTestStreamer(t, "--publish-unknown")
+	d := s.newDecoder(false)
+
+	decode := func(label uint32) {
+		data := serializeFrame(t,
+			&layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: layers.EthernetTypeMPLSUnicast},
+			&layers.MPLS{Label: label, StackBottom: true, TTL: 255},
+			&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast, EthernetType: layers.EthernetTypeARP},
+			gopacket.Payload(make([]byte, 46)),
+		)
+		ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+		if _, err := d.decode(data, ci, "eth0"); err != nil {
+			t.Fatalf("The frame of the label %d should be decoded, but was '%v'", label, err)
+		}
+	}
+
+	// The state is not kept for the label not found
+	decode(999)
+	if _, ok := d.pws[labelKey{"", 999}]; ok {
+		t.Error("The state should not be kept for the label not found")
+	}
+
+	// The state of the new PW is not kept while the states are full of the PWs seen recently
+	for i := 0; i < maxPWStates; i++ {
+		d.pws[labelKey{"eth1", uint32(i)}] = &pwState{updated: time.Now()}
+	}
+	decode(300)
+	if _, ok := d.pws[labelKey{"", 300}]; ok {
+		t.Errorf("The state of the new PW should not be kept over %d PWs", maxPWStates)
+	}
+
+	// The PWs not seen any more are pruned a minute after the last pruning
+	for _, pw := range d.pws {
+		pw.updated = time.Now().Add(-pwStateTTL - time.Minute)
+	}
+	d.pruned = d.pruned.Add(-time.Minute)
+	decode(300)
+	if _, ok := d.pws[labelKey{"", 300}]; !ok || len(d.pws) != 1 {
+		t.Errorf("The stale PWs should be pruned for the new PW, but was '%d' PWs", len(d.pws))
+	}
+}
+
+func TestKeyer(t *testing.T) {
+	s := newTestStreamer(t, "--label-context", "interface")
+	d := s.newDecoder(false)
+	k := s.newKeyer()
+
+	evi := func(labels ...uint32) []byte {
+		ls := []gopacket.SerializableLayer{
+			&layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: layers.EthernetTypeMPLSUnicast},
+		}
+		for i, l := range labels {
+			ls = append(ls, &layers.MPLS{Label: l, StackBottom: i == len(labels)-1, TTL: 255})
+		}
+		ls = append(ls,
+			&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast, EthernetType: layers.EthernetTypeARP},
+			gopacket.Payload(make([]byte, 46)),
+		)
+		return serializeFrame(t, ls...)
+	}
+
+	// The keyer does not look up the label store, and the label not cached is keyed as it is
+	if k.key(evi(400, 401), "eth0") != hashKey("eth0", 401) {
+		t.Error("The frame with the ESI label not cached should be dispatched as the ESI label")
+	}
+	if _, ok := s.cache.Peek(labelKey{"eth0", 401}); ok {
+		t.Error("The ESI label should not be looked up by the keyer")
+	}
+
+	// The PW is dispatched by the key of its state in the decoder
+	data := evi(400, 401)
+	ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+	if _, err := d.decode(data, ci, "eth0"); err != nil {
+		t.Fatalf("The frame should be decoded, but was '%v'", err)
+	}
+	key := k.key(evi(400), "eth0")
+	for pk := range d.pws {
+		if hashKey(pk.context, pk.label) != key {
+			t.Errorf("The PW '%v' should be dispatched by the hash of its key", pk)
+		}
+	}
+
+	// The frames of the EVI with and without the ESI label are the same PW
+	if k.key(evi(400, 401), "eth0") != key {
+		t.Error("The frame with the ESI label should be dispatched as the EVI label above it")
+	}
+	if k.key(evi(400), "eth1") == key {
+		t.Error("The frames of the label in the other context should be dispatched as the other PW")
+	}
+}
+
+// BenchmarkDecode decodes the mirrored frames into the packets published to the subscribers.
+func BenchmarkDecode(b *testing.B) {
+	for name, data := range testFrames(b) {
+		b.Run(name, func(b *testing.B) {
+			s := newTestStreamer(b)
+			d := s.newDecoder(false)
+			ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				if _, err := d.decode(data, ci, "eth0"); err != nil {
+					b.Fatal("Failed to decode frame:", err)
+				}
+			}
+
+			b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "pkts/s")
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/bpf"
+
+	"github.com/haccht/vplsbh/l2vpn"
+)
+
+// maxFanoutLabels is the depth of the label stack walked by the fanout program.
+const maxFanoutLabels = 8
+
+// fanoutAsm assembles the classic BPF program with the jumps to the named labels.
+type fanoutAsm struct {
+	insts  []bpf.Instruction
+	labels map[string]int
+	jumps  map[int][2]string
+}
+
+func (a *fanoutAsm) emit(insts ...bpf.Instruction) {
+	a.insts = append(a.insts, insts...)
+}
+
+func (a *fanoutAsm) label(name string) {
+	a.labels[name] = len(a.insts)
+}
+
+// jumpIf jumps to the label t if the condition holds, or to the label f otherwise.
+// The empty label means the next instruction.
+func (a *fanoutAsm) jumpIf(cond bpf.JumpTest, val uint32, t, f string) {
+	a.jumps[len(a.insts)] = [2]string{t, f}
+	a.emit(bpf.JumpIf{Cond: cond, Val: val})
+}
+
+func (a *fanoutAsm) jump(name string) {
+	a.jumps[len(a.insts)] = [2]string{name}
+	a.emit(bpf.Jump{})
+}
+
+func (a *fanoutAsm) assemble() ([]bpf.Instruction, error) {
+	skip := func(from int, name string) (uint32, error) {
+		if name == "" {
+			return 0, nil
+		}
+		to, ok := a.labels[name]
+		if !ok || to <= from {
+			return 0, fmt.Errorf("invalid jump to '%s'", name)
+		}
+		return uint32(to - from - 1), nil
+	}
+
+	for i, names := range a.jumps {
+		t, err := skip(i, names[0])
+		if err != nil {
+			return nil, err
+		}
+		f, err := skip(i, names[1])
+		if err != nil {
+			return nil, err
+		}
+
+		switch inst := a.insts[i].(type) {
+		case bpf.JumpIf:
+			if t > 0xff || f > 0xff {
+				return nil, fmt.Errorf("too far jump to '%s'", names)
+			}
+			inst.SkipTrue, inst.SkipFalse = uint8(t), uint8(f)
+			a.insts[i] = inst
+		case bpf.Jump:
+			inst.Skip = t
+			a.insts[i] = inst
+		}
+	}
+
+	return a.insts, nil
+}
+
+// fanoutProgram returns the program which tells the PW label of the frame, or the VNI of VXLAN,
+// for the kernel to steer the frames of a PW to the same socket of the fanout group.
+// The frame is loaded from the offset base, and the frames not to be told are steered to the first socket.
+// The PW label is taken at pwLabelIndex from the bottom of the stack, above the FAT flow label if fat is set
+// or if the bottom label has the TTL 0 as the decoder tells it.
+func fanoutProgram(base uint32, pwLabelIndex int, fat bool) ([]bpf.Instruction, error) {
+	a := &fanoutAsm{labels: make(map[string]int), jumps: make(map[int][2]string)}
+	ld := func(off uint32, size int) bpf.Instruction {
+		return bpf.LoadIndirect{Off: base + off, Size: size}
+	}
+
+	// X holds the offset of the header being parsed
+	a.emit(bpf.LoadConstant{Dst: bpf.RegX, Val: 14}, bpf.LoadAbsolute{Off: base + 12, Size: 2})
+	a.jumpIf(bpf.JumpEqual, uint32(layers.EthernetTypeDot1Q), "vlan", "")
+	a.jumpIf(bpf.JumpEqual, uint32(layers.EthernetTypeQinQ), "vlan", "l3")
+
+	// The outer tag is stripped by the kernel, and the inner one is left in the frame
+	a.label("vlan")
+	a.emit(bpf.LoadConstant{Dst: bpf.RegX, Val: 18}, bpf.LoadAbsolute{Off: base + 16, Size: 2})
+
+	a.label("l3")
+	a.jumpIf(bpf.JumpEqual, uint32(layers.EthernetTypeMPLSUnicast), "mpls", "")
+	a.jumpIf(bpf.JumpEqual, uint32(layers.EthernetTypeMPLSMulticast), "mpls", "")
+	a.jumpIf(bpf.JumpEqual, uint32(layers.EthernetTypeIPv4), "", "none")
+
+	// IPv4 but the fragments
+	a.emit(ld(6, 2))
+	a.jumpIf(bpf.JumpBitsSet, 0x1fff, "none", "")
+	a.emit(
+		ld(9, 1),
+		bpf.StoreScratch{Src: bpf.RegA, N: 0},
+		ld(0, 1),
+		bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: 0xf},
+		bpf.ALUOpConstant{Op: bpf.ALUOpShiftLeft, Val: 2},
+		bpf.ALUOpX{Op: bpf.ALUOpAdd},
+		bpf.TAX{},
+		bpf.LoadScratch{Dst: bpf.RegA, N: 0},
+	)
+	a.jumpIf(bpf.JumpEqual, uint32(layers.IPProtocolGRE), "gre", "")
+	a.jumpIf(bpf.JumpEqual, uint32(layers.IPProtocolUDP), "udp", "none")
+
+	// GRE without the optional fields
+	a.label("gre")
+	a.emit(ld(0, 2))
+	a.jumpIf(bpf.JumpEqual, 0, "", "none")
+	a.emit(ld(2, 2))
+	a.jumpIf(bpf.JumpEqual, uint32(layers.EthernetTypeMPLSUnicast), "", "none")
+	a.emit(bpf.TXA{}, bpf.ALUOpConstant{Op: bpf.ALUOpAdd, Val: 4}, bpf.TAX{})
+	a.jump("mpls")
+
+	a.label("udp")
+	a.emit(ld(2, 2))
+	a.jumpIf(bpf.JumpEqual, uint32(l2vpn.UDPPortMPLS), "", "vxlan")
+	a.emit(bpf.TXA{}, bpf.ALUOpConstant{Op: bpf.ALUOpAdd, Val: 8}, bpf.TAX{})
+	a.jump("mpls")
+
+	a.label("vxlan")
+	a.jumpIf(bpf.JumpEqual, uint32(l2vpn.UDPPortVXLAN), "", "none")
+	a.emit(ld(12, 4), bpf.ALUOpConstant{Op: bpf.ALUOpShiftRight, Val: 8}, bpf.RetA{})
+
+	// Find the bottom of the stack
+	a.label("mpls")
+	for i := 0; i < maxFanoutLabels; i++ {
+		a.emit(ld(uint32(4*i), 4))
+		a.jumpIf(bpf.JumpBitsSet, 0x100, fmt.Sprintf("bottom%d", i), "")
+	}
+	a.jump("none")
+
+	// The PW label at the depth from the bottom of the stack
+	pick := func(i, depth int) {
+		if i < depth {
+			a.jump("none")
+			return
+		}
+		a.emit(ld(uint32(4*(i-depth)), 4), bpf.ALUOpConstant{Op: bpf.ALUOpShiftRight, Val: 12}, bpf.RetA{})
+	}
+
+	for i := 0; i < maxFanoutLabels; i++ {
+		a.label(fmt.Sprintf("bottom%d", i))
+		if fat {
+			pick(i, pwLabelIndex+1)
+			continue
+		}
+
+		// The bottom label with the TTL 0 is the flow label, but the entropy label following the ELI
+		a.jumpIf(bpf.JumpBitsSet, 0xff, fmt.Sprintf("nofat%d", i), "")
+		if i > 0 {
+			a.emit(ld(uint32(4*(i-1)), 4), bpf.ALUOpConstant{Op: bpf.ALUOpShiftRight, Val: 12})
+			a.jumpIf(bpf.JumpEqual, l2vpn.LabelELI, fmt.Sprintf("nofat%d", i), "")
+		}
+		pick(i, pwLabelIndex+1)
+
+		a.label(fmt.Sprintf("nofat%d", i))
+		pick(i, pwLabelIndex)
+	}
+
+	a.label("none")
+	a.emit(bpf.RetConstant{Val: 0})
+
+	return a.assemble()
+}
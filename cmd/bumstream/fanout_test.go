@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/bpf"
+
+	"github.com/haccht/vplsbh/l2vpn"
+)
+
+func TestFanoutProgram(t *testing.T) {
+	inner := []gopacket.SerializableLayer{
+		&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast, EthernetType: layers.EthernetTypeARP},
+		gopacket.Payload(make([]byte, 46)),
+	}
+	eth := func(t layers.EthernetType) *layers.Ethernet {
+		return &layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: t}
+	}
+	ipv4 := func(p layers.IPProtocol) *layers.IPv4 {
+		return &layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: p, SrcIP: net.IP{192, 0, 2, 1}, DstIP: net.IP{192, 0, 2, 2}}
+	}
+	stack := func(labels ...uint32) []gopacket.SerializableLayer {
+		var ls []gopacket.SerializableLayer
+		for i, l := range labels {
+			ls = append(ls, &layers.MPLS{Label: l, StackBottom: i == len(labels)-1, TTL: 255})
+		}
+		return append(ls, inner...)
+	}
+	frame := func(ls ...gopacket.SerializableLayer) []byte {
+		return serializeFrame(t, ls...)
+	}
+
+	tests := []struct {
+		name         string
+		pwLabelIndex int
+		fat          bool
+		data         []byte
+		expected     int
+	}{
+		{"EoMPLS", 0, false, frame(append([]gopacket.SerializableLayer{eth(layers.EthernetTypeMPLSUnicast)}, stack(16000, 100)...)...), 100},
+		{"EoMPLSFAT", 0, true, frame(append([]gopacket.SerializableLayer{eth(layers.EthernetTypeMPLSUnicast)}, stack(16000, 100, 54321)...)...), 100},
+		{"EoMPLSFATTTL", 0, false, frame(append([]gopacket.SerializableLayer{eth(layers.EthernetTypeMPLSUnicast)}, append(stack(16000, 100), &layers.MPLS{Label: 54321, StackBottom: true})...)...), 100},
+		{"EoMPLSIndex", 1, false, frame(append([]gopacket.SerializableLayer{eth(layers.EthernetTypeMPLSUnicast)}, stack(16000, 100, 200)...)...), 100},
+		{"QinQ", 0, false, frame(append([]gopacket.SerializableLayer{
+			eth(layers.EthernetTypeDot1Q),
+			&layers.Dot1Q{VLANIdentifier: 100, Type: layers.EthernetTypeMPLSUnicast},
+		}, stack(100)...)...), 100},
+		{"GRE", 0, false, frame(append([]gopacket.SerializableLayer{
+			eth(layers.EthernetTypeIPv4), ipv4(layers.IPProtocolGRE),
+			&layers.GRE{Protocol: layers.EthernetTypeMPLSUnicast},
+		}, stack(100)...)...), 100},
+		{"UDP", 0, false, frame(append([]gopacket.SerializableLayer{
+			eth(layers.EthernetTypeIPv4), ipv4(layers.IPProtocolUDP),
+			&layers.UDP{SrcPort: 49152, DstPort: l2vpn.UDPPortMPLS},
+		}, stack(100)...)...), 100},
+		{"VXLAN", 0, false, testFrames(t)["VXLAN"], 5000},
+		{"NoPWLabel", 1, false, frame(append([]gopacket.SerializableLayer{eth(layers.EthernetTypeMPLSUnicast)}, stack(100)...)...), 0},
+		{"IPv6", 0, false, frame(eth(layers.EthernetTypeIPv6), gopacket.Payload(make([]byte, 46))), 0},
+	}
+
+	for _, tt := range tests {
+		insts, err := fanoutProgram(0, tt.pwLabelIndex, tt.fat)
+		if err != nil {
+			t.Fatal("Failed to assemble fanout program:", err)
+		}
+		vm, err := bpf.NewVM(insts)
+		if err != nil {
+			t.Fatal("Failed to load fanout program:", err)
+		}
+
+		key, err := vm.Run(tt.data)
+		if err != nil {
+			t.Fatalf("Failed to run fanout program on the %s frame: %v", tt.name, err)
+		}
+		if key != tt.expected {
+			t.Errorf("The %s frame should be steered by '%d', but was '%d'", tt.name, tt.expected, key)
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
-	"github.com/gomodule/redigo/redis"
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
@@ -18,11 +26,11 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
-	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/haccht/vplsbh/cache"
 	"github.com/haccht/vplsbh/l2vpn"
 	pb "github.com/haccht/vplsbh/pkg/grpc"
+	"github.com/haccht/vplsbh/resolver"
 )
 
 const (
@@ -40,9 +48,22 @@ func getEnv(key, fallback string) string {
 }
 
 type cmdOption struct {
-	Address   string `short:"a" long:"addr"      description:"gRPC address to serve" value-name:"<addr>" default:"127.0.0.1:50005"`
-	Interface string `short:"i" long:"interface" description:"Read packets from the interface" value-name:"<interface>"`
-	Filepath  string `short:"r" long:"read"      description:"Read packets from the pcap file" hidden:"true"`
+	Address       string   `short:"a" long:"addr"      description:"gRPC address to serve" value-name:"<addr>" default:"127.0.0.1:50005"`
+	Interface     []string `short:"i" long:"interface" description:"Read packets from the interface, repeat for multiple interfaces" value-name:"<interface>"`
+	Filepath      []string `short:"r" long:"read"      description:"Read packets from the pcap or pcapng file, repeat for multiple files" value-name:"<filepath>"`
+	Speed         float64  `long:"speed"               description:"Pace packets read from the file by their timestamps scaled by the factor, 0 for no wait" value-name:"<factor>" default:"0"`
+	Loop          uint     `long:"loop"                description:"Read the file the specified times, 0 for endless" value-name:"<count>" default:"1"`
+	PWLabelIndex  uint     `short:"l" long:"pw-label"  description:"Position of the PW label counted from the bottom of the label stack" value-name:"<index>" default:"0"`
+	FAT           bool     `long:"fat"                 description:"Assume a FAT flow label (RFC 6391) under the PW label without the FAT attribute"`
+	Capture       string   `long:"capture"             description:"Capture packets with libpcap or AF_PACKET TPACKET_V3" choice:"pcap" choice:"afpacket" default:"pcap"`
+	Workers       uint     `short:"n" long:"workers"   description:"Number of workers to decode packets" value-name:"<count>" default:"1"`
+	Filter        string   `short:"f" long:"filter"    description:"Capture only packets matching the BPF primitive" value-name:"<expression>"`
+	StatsInterval uint     `long:"stats-interval"      description:"Interval time in sec to log the capture stats, 0 to disable" value-name:"<seconds>" default:"60"`
+	NegativeTTL   uint     `long:"negative-ttl"        description:"Time in sec to cache the labels not found, 0 to disable" value-name:"<seconds>" default:"30"`
+	RefreshAhead  uint     `long:"refresh-ahead"       description:"Time in sec before the expiration to refresh the labels in background, 0 to disable" value-name:"<seconds>" default:"60"`
+	Unknown       bool     `long:"publish-unknown"     description:"Publish the frames with the label not found as the unknown domain and remote"`
+	LabelContext  string   `long:"label-context"       description:"Resolve the labels per PE in the context of the interface, the outer MAC or the LDP neighbor of the outer destination MAC" choice:"none" choice:"interface" choice:"srcmac" choice:"dstmac" choice:"neighbor" default:"none"`
+	Resolver      string   `long:"resolver"            description:"Resolve the labels with redis://, http(s):// or the YAML, JSON or CSV file (default: $REDIS_URL or redis://localhost:6379)" value-name:"<url>"`
 }
 
 func NewCmdOption(args []string) (*cmdOption, error) {
@@ -53,118 +74,432 @@ func NewCmdOption(args []string) (*cmdOption, error) {
 		return nil, err
 	}
 
-	if opt.Interface == "" && opt.Filepath == "" {
+	if len(opt.Interface) == 0 && len(opt.Filepath) == 0 {
 		return nil, fmt.Errorf("the required flag '-i' was not specified")
 	}
 
+	if opt.Capture == captureAFPacket && len(opt.Filepath) > 0 {
+		return nil, fmt.Errorf("AF_PACKET can not read the pcap file")
+	}
+
+	if opt.Speed < 0 {
+		return nil, fmt.Errorf("the speed must not be negative")
+	}
+
+	if opt.Workers == 0 {
+		opt.Workers = 1
+	}
+
+	if opt.Resolver == "" {
+		opt.Resolver = getEnv("REDIS_URL", redisURL)
+	}
+
 	return &opt, nil
 }
 
-type streamer struct {
-	sync.RWMutex
+// Type attribute of the label resolved. The label without Type is a VPLS PW label.
+const (
+	labelTypeEVPN = "EVPN"
+	labelTypeESI  = "ESI"
+)
+
+// Context of the label assigned per PE.
+const (
+	labelContextNone      = "none"
+	labelContextInterface = "interface"
+	labelContextSrcMAC    = "srcmac"
+	labelContextDstMAC    = "dstmac"
+	labelContextNeighbor  = "neighbor"
+)
 
-	cache    *cache.TTLCache
-	channels map[string]chan *pb.Packet
+// vniKey and vtepKey are the cache keys of VXLAN, and isidKey is of PBB, while the label is keyed by uint32.
+// labelKey is the label in the context of the PE, and macKey is the outer MAC to resolve the LDP neighbor.
+type vniKey uint32
+type vtepKey string
+type isidKey uint32
+type macKey string
+
+type labelKey struct {
+	context string
+	label   uint32
 }
 
-func NewStreamer() *streamer {
-	// Redis pool to lookup when the label key would not be found or expited.
-	r := &redis.Pool{
-		MaxIdle:     2,
-		MaxActive:   4,
-		IdleTimeout: 5 * time.Minute,
-		Dial:        func() (redis.Conn, error) { return redis.DialURL(getEnv("REDIS_URL", redisURL)) },
+// resolveKey returns the key to resolve of the cache key.
+func resolveKey(k interface{}) string {
+	switch k := k.(type) {
+	case vniKey:
+		return fmt.Sprintf("vni:%d", k)
+	case vtepKey:
+		return fmt.Sprintf("vtep:%s", k)
+	case isidKey:
+		return fmt.Sprintf("isid:%d", k)
+	case macKey:
+		return fmt.Sprintf("mac:%s", k)
+	case labelKey:
+		return fmt.Sprintf("label:%s:%d", k.context, k.label)
+	default:
+		return fmt.Sprintf("label:%d", k)
+	}
+}
+
+// cacheKey returns the cache key of the key to resolve, which is the reverse of resolveKey.
+func cacheKey(key string) (interface{}, bool) {
+	prefix, v, ok := strings.Cut(key, ":")
+	if !ok {
+		return nil, false
+	}
+
+	switch prefix {
+	case "vtep":
+		return vtepKey(v), true
+	case "mac":
+		return macKey(v), true
+	}
+
+	// The label in the context is separated by the last colon, as the context may be the MAC
+	var pe string
+	if i := strings.LastIndexByte(v, ':'); i >= 0 && prefix == "label" {
+		pe, v = v[:i], v[i+1:]
+	}
+
+	n, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return nil, false
 	}
 
+	switch prefix {
+	case "label":
+		if pe != "" {
+			return labelKey{pe, uint32(n)}, true
+		}
+		return uint32(n), true
+	case "vni":
+		return vniKey(n), true
+	case "isid":
+		return isidKey(n), true
+	default:
+		return nil, false
+	}
+}
+
+const (
+	// maxPWStates bounds the PW states of each decoder against the frames with the junk labels resolved with no context.
+	maxPWStates = 65536
+	// pwStateTTL is the time to forget the PW not seen any more.
+	pwStateTTL = 24 * time.Hour
+)
+
+// pwState is the state of the PW learned from the received frames.
+// The state is kept only for the PW resolved by the label store.
+type pwState struct {
+	l2vpn.SequenceCounter
+	l2vpn.ControlWordDetector
+	Domain, Remote string
+
+	// info is the attributes of the label which the control word was detected with.
+	info resolver.Info
+	// updated is when the PW was seen last.
+	updated time.Time
+}
+
+// subscriber is the channel of the stream, counting the packets dropped while it is full.
+type subscriber struct {
+	dropped uint64
+	ch      chan *pb.Packet
+}
+
+type streamer struct {
+	// rejected is placed first to be 64-bit aligned for the atomic operations
+	rejected [numRejectReasons]uint64
+
+	sync.RWMutex
+
+	cache        *cache.TTLCache
+	subscribers  map[string]*subscriber
+	sources      []*source
+	pwLabelIndex int
+	fat          bool
+	labelContext string
+
+	publishUnknown bool
+
+	// decoders keep the state of the PWs and the unknown labels dispatched to each of them,
+	// which are not shared so that the workers never wait for each other.
+	decoders []*decoder
+
+	// closed tells that no more packets are published after the end of the files.
+	closed bool
+}
+
+func NewStreamer(opt *cmdOption, r resolver.Resolver) *streamer {
 	// Set a lookup function used when the label key would not be found or be expired.
+	// The labels not found are cached as negative not to flood the resolver with the frames of the unknown PW.
 	c := cache.NewTTLCache(5 * time.Minute)
+	c.SetNegativeExpiration(time.Duration(opt.NegativeTTL) * time.Second)
+	c.SetRefreshAhead(time.Duration(opt.RefreshAhead) * time.Second)
 	c.SetLookupFunc(func(k interface{}) (interface{}, bool) {
-		conn := r.Get()
-		defer conn.Close()
-
-		key := fmt.Sprintf("label:%d", k)
-		val, err := redis.Values(conn.Do("HMGET", key, "Domain", "Remote", "PeerID"))
+		key := resolveKey(k)
+		t, err := r.Resolve(key)
 		if err != nil {
+			if !errors.Is(err, resolver.ErrNotFound) {
+				log.Printf("failed to resolve %s: %v", key, err)
+			}
 			return nil, false
 		}
 
-		t := &struct{ Domain, Remote, PeerID string }{}
-		if _, err := redis.Scan(val, &t.Domain, &t.Remote, &t.PeerID); err != nil {
-			return nil, false
-		}
-
-		c.SetWithExpiration(k, t, cache.DefaultExpiration)
 		return t, true
 	})
 
 	return &streamer{
-		cache:    c,
-		channels: make(map[string]chan *pb.Packet, 10),
+		cache:        c,
+		subscribers:  make(map[string]*subscriber, 10),
+		pwLabelIndex: int(opt.PWLabelIndex),
+		fat:          opt.FAT,
+		labelContext: opt.LabelContext,
+
+		publishUnknown: opt.Unknown,
 	}
 
 }
 
-func (s *streamer) Serve(handle *pcap.Handle) error {
-	var eth layers.Ethernet
-	var vpls l2vpn.VPLS
-	var pwmcw l2vpn.PWMCW
-	var parser *gopacket.DecodingLayerParser
+// reloadOnSignal reloads the resolver on SIGHUP and flushes the cached labels.
+func (s *streamer) reloadOnSignal(rl resolver.Reloader) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	for range sig {
+		if err := rl.Reload(); err != nil {
+			log.Printf("failed to reload resolver: %v", err)
+			continue
+		}
 
-	decoded := make([]gopacket.LayerType, 0, 3)
+		s.cache.Flush()
+		s.resetControlWords(nil)
+		log.Println("reloaded resolver")
+
+		if pl, ok := rl.(resolver.Preloader); ok {
+			s.preload(pl)
+		}
+	}
+}
+
+// preload warms up the cache with all the mappings of the resolver.
+// The mappings invalidated by the watch while they are preloaded are left to be looked up again.
+func (s *streamer) preload(pl resolver.Preloader) {
+	var n int
+	err := s.cache.Load(func(set func(key, val interface{})) error {
+		return pl.Preload(func(key string, info *resolver.Info) {
+			if k, ok := cacheKey(key); ok {
+				set(k, info)
+				n++
+			}
+		})
+	})
+	if err != nil {
+		log.Printf("failed to preload labels: %v", err)
+		return
+	}
 
+	log.Printf("preloaded %d labels", n)
+}
+
+// watch invalidates the cached labels as soon as they are changed in the resolver.
+// The cache is flushed when the watch fails, as the changes may be missed until it is restarted.
+func (s *streamer) watch(w resolver.Watcher) {
 	for {
-		data, ci, err := handle.ZeroCopyReadPacketData()
-		if err != nil {
-			return err
+		err := w.Watch(func(key string) {
+			if k, ok := cacheKey(key); ok {
+				s.cache.Del(k)
+				s.resetControlWords(k)
+			}
+		})
+		log.Printf("failed to watch resolver: %v", err)
+
+		s.cache.Flush()
+		s.resetControlWords(nil)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// resetControlWords forgets the control word detected for the PWs of the cache key, or of all the PWs if k is nil,
+// as the PW may be signaled again with or without the control word when its label is changed.
+func (s *streamer) resetControlWords(k interface{}) {
+	for _, d := range s.allDecoders() {
+		d.pwLock.Lock()
+		for pk, pw := range d.pws {
+			switch k := k.(type) {
+			case nil:
+			case uint32:
+				if pk.label != k {
+					continue
+				}
+			case labelKey:
+				if pk != k {
+					continue
+				}
+			default:
+				continue
+			}
+			pw.ControlWordDetector.Reset()
 		}
+		d.pwLock.Unlock()
+	}
+}
+
+// frame is a mirrored frame handed from the reader to the worker.
+type frame struct {
+	data  []byte
+	ci    gopacket.CaptureInfo
+	iface string
+}
 
-		// Decode the outer Ethernet layer
-		parser = gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &eth)
-		parser.DecodeLayers(data, &decoded)
+// Serve reads the mirrored frames from the sources and decodes them with the workers.
+// The streams of the subscribers are closed when all the sources have reached the end.
+func (s *streamer) Serve(ctx context.Context, sources []*source, workers int) error {
+	s.Lock()
+	s.sources = sources
+	s.Unlock()
 
-		// Decode the VPLS and inner Ethernet layers
-		parser = gopacket.NewDecodingLayerParser(layers.LayerTypeMPLS, &vpls, &pwmcw, &eth)
-		parser.DecodeLayers(eth.Payload, &decoded)
+	err := s.serve(ctx, sources, workers)
+	s.closeStreams()
+
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// serve dispatches the frames to the workers by the PW, the label in its context, to keep the order in each PW.
+// The fanout sources are decoded in place, as their frames are steered by the PW in the kernel.
+// All the sources are closed when one of them fails, and its error is returned.
+func (s *streamer) serve(ctx context.Context, sources []*source, workers int) error {
+	g, ctx := errgroup.WithContext(ctx)
+
+	// The sources are closed to stop reading when one of them fails or the context is canceled
+	go func() {
+		<-ctx.Done()
+		closeSources(sources)
+	}()
+
+	// Decode the frames in place when there is nothing to dispatch
+	if len(sources) == 1 && workers <= 1 {
+		g.Go(func() error { return s.decodeInPlace(sources[0]) })
+		return g.Wait()
+	}
+
+	var dispatched bool
+	for _, src := range sources {
+		dispatched = dispatched || !src.fanout
+	}
+	if !dispatched {
+		workers = 0
+	}
 
-		if len(decoded) < 3 ||
-			decoded[0] != layers.LayerTypeMPLS ||
-			decoded[1] != l2vpn.LayerTypePWMCW ||
-			decoded[2] != layers.LayerTypeEthernet {
+	var wg sync.WaitGroup
+	chs := make([]chan frame, workers)
+	for i := range chs {
+		chs[i] = make(chan frame, 1000)
+
+		wg.Add(1)
+		go func(ch chan frame) {
+			defer wg.Done()
+
+			d := s.newDecoder(false)
+			for f := range ch {
+				s.handle(d, f.data, f.ci, f.iface)
+			}
+		}(chs[i])
+	}
+
+	for _, src := range sources {
+		src := src
+		if src.fanout {
+			g.Go(func() error { return s.decodeInPlace(src) })
 			continue
 		}
+		g.Go(func() error { return s.dispatch(src, chs) })
+	}
 
-		rawData := append(eth.Contents, eth.Payload...)
-		dupData := make([]byte, len(rawData))
-		copy(dupData, rawData)
+	err := g.Wait()
+	for _, ch := range chs {
+		close(ch)
+	}
+	wg.Wait()
 
-		v, ok := s.cache.Get(vpls.Label)
-		if !ok {
-			continue
+	return err
+}
+
+// dispatch reads the frames from the source and hands their copies to the workers.
+func (s *streamer) dispatch(src *source, chs []chan frame) error {
+	var sl slab
+
+	k := s.newKeyer()
+	for {
+		data, ci, err := src.ZeroCopyReadPacketData()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", src.name, err)
 		}
 
-		t := v.(*struct{ Domain, Remote, PeerID string })
-		p := &pb.Packet{
-			Data:      dupData,
-			Label:     vpls.Label,
-			Domain:    t.Domain,
-			Remote:    t.Remote,
-			Peerid:    t.PeerID,
-			Timestamp: timestamppb.New(ci.Timestamp),
+		// The data is overwritten by the next read, so that it is copied here once for all
+		dupData := sl.copy(data)
+
+		chs[k.key(dupData, src.name)%uint32(len(chs))] <- frame{data: dupData, ci: ci, iface: src.name}
+	}
+}
+
+// decodeInPlace decodes the frames of the source with the decoder of its own.
+func (s *streamer) decodeInPlace(src *source) error {
+	d := s.newDecoder(true)
+	for {
+		data, ci, err := src.ZeroCopyReadPacketData()
+		if err == io.EOF {
+			return nil
 		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", src.name, err)
+		}
+		s.handle(d, data, ci, src.name)
+	}
+}
 
-		s.Publish(p)
+// handle decodes the frame read from the interface and publishes the packet.
+func (s *streamer) handle(d *decoder, data []byte, ci gopacket.CaptureInfo, iface string) {
+	p, err := d.decode(data, ci, iface)
+	if err != nil {
+		s.reject(err)
+		return
 	}
+	p.Interface = iface
+
+	s.Publish(p)
+}
+
+// reject counts the frame which could not be published by the reason.
+func (s *streamer) reject(err error) {
+	atomic.AddUint64(&s.rejected[rejectReasonOf(err)], 1)
+}
+
+// allDecoders returns the decoders created so far.
+func (s *streamer) allDecoders() []*decoder {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.decoders
 }
 
 func (s *streamer) Publish(p *pb.Packet) {
 	s.RLock()
 	defer s.RUnlock()
 
-	for _, ch := range s.channels {
+	for _, sub := range s.subscribers {
 		select {
-		case ch <- p:
+		case sub.ch <- p:
 		default:
 			// Ignore the packet if the channel is full
+			atomic.AddUint64(&sub.dropped, 1)
 		}
 	}
 }
@@ -174,8 +509,14 @@ func (s *streamer) Subscribe(id string) chan *pb.Packet {
 	defer s.Unlock()
 
 	log.Printf("[%s] register a new stream", id)
-	s.channels[id] = make(chan *pb.Packet, 1000)
-	return s.channels[id]
+	ch := make(chan *pb.Packet, 1000)
+	if s.closed {
+		close(ch)
+		return ch
+	}
+
+	s.subscribers[id] = &subscriber{ch: ch}
+	return ch
 }
 
 func (s *streamer) Unsubscribe(id string) {
@@ -183,8 +524,22 @@ func (s *streamer) Unsubscribe(id string) {
 	defer s.Unlock()
 
 	log.Printf("[%s] unregister the stream", id)
-	close(s.channels[id])
-	delete(s.channels, id)
+	if sub, ok := s.subscribers[id]; ok {
+		close(sub.ch)
+		delete(s.subscribers, id)
+	}
+}
+
+// closeStreams closes the channels of the subscribers, whose streams end with EOF after the remaining packets.
+func (s *streamer) closeStreams() {
+	s.Lock()
+	defer s.Unlock()
+
+	for id, sub := range s.subscribers {
+		close(sub.ch)
+		delete(s.subscribers, id)
+	}
+	s.closed = true
 }
 
 func (s *streamer) Sniff(req *pb.Request, stream pb.BumSniffService_SniffServer) (err error) {
@@ -201,6 +556,10 @@ func (s *streamer) Sniff(req *pb.Request, stream pb.BumSniffService_SniffServer)
 	defer s.Unsubscribe(id)
 
 	for packet := range ch {
+		if req.Kind != packet.Kind {
+			continue
+		}
+
 		if req.Remote != "" && req.Remote != packet.Remote {
 			continue
 		}
@@ -209,7 +568,20 @@ func (s *streamer) Sniff(req *pb.Request, stream pb.BumSniffService_SniffServer)
 			continue
 		}
 
-		if req.Filter != "" {
+		if req.Interface != "" && req.Interface != packet.Interface {
+			continue
+		}
+
+		if req.Svlan != 0 && req.Svlan != packet.Svlan {
+			continue
+		}
+
+		if req.Cvlan != 0 && req.Cvlan != packet.Cvlan {
+			continue
+		}
+
+		// OAM messages are not Ethernet frames to match with the BPF filter
+		if req.Filter != "" && packet.Kind == pb.PacketKind_DATA {
 			ci := gopacket.CaptureInfo{
 				Timestamp:     packet.Timestamp.AsTime(),
 				CaptureLength: len(packet.Data),
@@ -229,11 +601,95 @@ func (s *streamer) Sniff(req *pb.Request, stream pb.BumSniffService_SniffServer)
 	return nil
 }
 
-func main() {
-	// MPLS Decoder should assume that the MPLS payload is a Ethenet frame with a control-word header
-	layers.MPLSPayloadDecoder = &l2vpn.PWMCWDecoder{ControlWord: true}
+// captureStats returns the capture stats per interface sorted by the interface.
+func (s *streamer) captureStats() ([]*pb.CaptureStats, error) {
+	s.RLock()
+	stats, err := sourceStats(s.sources)
+	s.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var captures []*pb.CaptureStats
+	for iface, st := range stats {
+		captures = append(captures, &pb.CaptureStats{
+			Interface: iface,
+			Received:  st.Received,
+			Dropped:   st.Dropped,
+			Ifdropped: st.IfDropped,
+		})
+	}
+
+	sort.Slice(captures, func(i, j int) bool { return captures[i].Interface < captures[j].Interface })
+	return captures, nil
+}
+
+// logCaptureStats logs the capture stats periodically to tell the capture loss.
+func (s *streamer) logCaptureStats(interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
 
-	ss := NewStreamer()
+	for range tick.C {
+		captures, err := s.captureStats()
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		for _, c := range captures {
+			log.Printf("[%s] received %d, dropped %d, if-dropped %d packets", c.Interface, c.Received, c.Dropped, c.Ifdropped)
+		}
+	}
+}
+
+func (s *streamer) Stats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsReply, error) {
+	captures, err := s.captureStats()
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &pb.StatsReply{Captures: captures}
+
+	s.RLock()
+	for id, sub := range s.subscribers {
+		reply.Subscribers = append(reply.Subscribers, &pb.SubscriberStats{Id: id, Dropped: atomic.LoadUint64(&sub.dropped)})
+	}
+	s.RUnlock()
+	sort.Slice(reply.Subscribers, func(i, j int) bool { return reply.Subscribers[i].Id < reply.Subscribers[j].Id })
+
+	// The frames of a PW may be decoded by more than one decoder, as the fanout of the kernel
+	// steers the frames with the ESI label by the ESI label
+	merged := make(map[labelKey]*pb.PWStats)
+	for _, d := range s.allDecoders() {
+		for _, st := range d.pwStats() {
+			k := labelKey{st.Context, st.Label}
+			if m, ok := merged[k]; ok {
+				m.Received += st.Received
+				m.Lost += st.Lost
+				m.Outoforder += st.Outoforder
+				m.Duplicated += st.Duplicated
+				continue
+			}
+			merged[k] = st
+			reply.Pwstats = append(reply.Pwstats, st)
+		}
+	}
+
+	sort.Slice(reply.Pwstats, func(i, j int) bool {
+		if reply.Pwstats[i].Context != reply.Pwstats[j].Context {
+			return reply.Pwstats[i].Context < reply.Pwstats[j].Context
+		}
+		return reply.Pwstats[i].Label < reply.Pwstats[j].Label
+	})
+
+	reply.Rejected = make(map[string]uint64, numRejectReasons)
+	for r := rejectReason(0); r < numRejectReasons; r++ {
+		reply.Rejected[r.String()] = atomic.LoadUint64(&s.rejected[r])
+	}
+	return reply, nil
+}
+
+func main() {
 	opt, err := NewCmdOption(os.Args)
 	if err != nil {
 		if fe, ok := err.(*flags.Error); ok && fe.Type == flags.ErrHelp {
@@ -243,7 +699,46 @@ func main() {
 		os.Exit(1)
 	}
 
-	var errGroup errgroup.Group
+	r, err := resolver.Open(opt.Resolver)
+	if err != nil {
+		log.Fatalf("failed to open resolver: %v", err)
+	}
+
+	ss := NewStreamer(opt, r)
+
+	// The file is reloaded on SIGHUP, and the labels are looked up again
+	if rl, ok := r.(resolver.Reloader); ok {
+		go ss.reloadOnSignal(rl)
+	}
+
+	// The labels changed are invalidated immediately, such as by re-provisioning the PW
+	if w, ok := r.(resolver.Watcher); ok {
+		if rd, ok := r.(*resolver.Redis); ok {
+			ev, err := rd.KeyspaceEvents()
+			if err == nil && (!strings.Contains(ev, "K") || !strings.ContainsAny(ev, "hA")) {
+				log.Printf("keyspace notifications are disabled, enable them with 'CONFIG SET notify-keyspace-events Khg' or publish the keys changed to %s", resolver.InvalidateChannel)
+			}
+		}
+
+		go ss.watch(w)
+	}
+
+	// The labels are preloaded not to slow down the first packets with the lookups
+	if pl, ok := r.(resolver.Preloader); ok {
+		ss.preload(pl)
+	}
+
+	errGroup, ctx := errgroup.WithContext(context.Background())
+
+	kaep := keepalive.EnforcementPolicy{MinTime: 10 * time.Second}
+	gs := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(kaep))
+	pb.RegisterBumSniffServiceServer(gs, ss)
+
+	// The servers are stopped to exit when either of them fails
+	go func() {
+		<-ctx.Done()
+		gs.Stop()
+	}()
 
 	errGroup.Go(func() error {
 		log.Println("start gRPC server")
@@ -253,9 +748,6 @@ func main() {
 			return fmt.Errorf("failed to listen: %v", err)
 		}
 
-		kaep := keepalive.EnforcementPolicy{MinTime: 10 * time.Second}
-		gs := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(kaep))
-		pb.RegisterBumSniffServiceServer(gs, ss)
 		if err := gs.Serve(li); err != nil {
 			return fmt.Errorf("failed to start gRPC server: %v", err)
 		}
@@ -266,32 +758,27 @@ func main() {
 	errGroup.Go(func() error {
 		log.Println("start BUM sniffer server")
 
-		var openHandle func() (*pcap.Handle, error)
-		if opt.Interface != "" {
-			openHandle = func() (*pcap.Handle, error) {
-				return pcap.OpenLive(opt.Interface, snapshotLen, promiscuous, pcap.BlockForever)
-			}
-		} else if opt.Filepath != "" {
-			openHandle = func() (*pcap.Handle, error) {
-				return pcap.OpenOffline(opt.Filepath)
-			}
+		sources, err := openSources(opt)
+		if err != nil {
+			return err
 		}
+		defer closeSources(sources)
 
-		ha, err := openHandle()
-		if err != nil {
-			return fmt.Errorf("failed to open pcap handle")
+		if opt.StatsInterval != 0 {
+			go ss.logCaptureStats(time.Duration(opt.StatsInterval) * time.Second)
 		}
-		defer ha.Close()
 
-		if err := ss.Serve(ha); err != nil {
+		if err := ss.Serve(ctx, sources, int(opt.Workers)); err != nil {
 			return fmt.Errorf("failed to start BUM stream server: %v", err)
 		}
 
+		// The gRPC server keeps serving the stats after the end of the files
+		log.Println("finished reading packets")
+
 		return nil
 	})
 
 	if err := errGroup.Wait(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// blockingSource blocks the read until it is closed, as the interface receiving nothing.
+type blockingSource struct {
+	closed chan struct{}
+	err    error
+}
+
+func (b *blockingSource) ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	if b.err != nil {
+		return nil, gopacket.CaptureInfo{}, b.err
+	}
+	<-b.closed
+	return nil, gopacket.CaptureInfo{}, io.EOF
+}
+
+func (b *blockingSource) CaptureStats() (captureStats, error) {
+	return captureStats{}, nil
+}
+
+func (b *blockingSource) Close() {
+	select {
+	case <-b.closed:
+	default:
+		close(b.closed)
+	}
+}
+
+func TestServeFailure(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		s := newTestStreamer(t)
+
+		failure := errors.New("interface down")
+		sources := []*source{
+			{packetSource: &blockingSource{closed: make(chan struct{})}, name: "eth0"},
+			{packetSource: &blockingSource{closed: make(chan struct{}), err: failure}, name: "eth1"},
+		}
+
+		done := make(chan error)
+		go func() { done <- s.Serve(context.Background(), sources, workers) }()
+
+		select {
+		case err := <-done:
+			if err == nil || !strings.Contains(err.Error(), failure.Error()) {
+				t.Errorf("The error of the source should be returned, but was '%v'", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("The sources should be stopped when one of them fails with %d workers", workers)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		k   interface{}
+		key string
+	}{
+		{uint32(100), "label:100"},
+		{labelKey{"eth0", 100}, "label:eth0:100"},
+		{labelKey{"cc:15:14:64:00:01", 100}, "label:cc:15:14:64:00:01:100"},
+		{labelKey{"192.0.2.1", 100}, "label:192.0.2.1:100"},
+		{labelKey{"2001:db8::1", 100}, "label:2001:db8::1:100"},
+		{labelKey{"2001:db8::", 100}, "label:2001:db8:::100"},
+		{vniKey(5000), "vni:5000"},
+		{vtepKey("2001:db8::1"), "vtep:2001:db8::1"},
+		{isidKey(10000), "isid:10000"},
+		{macKey("cc:15:14:64:00:01"), "mac:cc:15:14:64:00:01"},
+	}
+
+	for _, tt := range tests {
+		if key := resolveKey(tt.k); key != tt.key {
+			t.Errorf("The key to resolve of '%#v' should be '%s', but was '%s'", tt.k, tt.key, key)
+		}
+		if k, ok := cacheKey(tt.key); !ok || k != tt.k {
+			t.Errorf("The cache key of '%s' should be '%#v', but was '%#v'", tt.key, tt.k, k)
+		}
+	}
+
+	for _, key := range []string{"label", "label:", "label:eth0:", "label:4294967296", "vni:eth0:100", "isid:x", "peer:100"} {
+		if k, ok := cacheKey(key); ok {
+			t.Errorf("The key '%s' should not be cached, but was '%#v'", key, k)
+		}
+	}
+}
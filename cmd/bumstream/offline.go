@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// pcapngMagic is the block type of the Section Header Block starting a pcapng file.
+var pcapngMagic = []byte{0x0a, 0x0d, 0x0d, 0x0a}
+
+type packetReader interface {
+	ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+}
+
+// offlineSource reads the frames from the pcap or pcapng file as if they were received now.
+// The frames are paced by their timestamps scaled by the speed, or read as fast as possible with the speed 0,
+// and their timestamps are shifted to the time when they are paced, or to the time when they are read.
+// The file is read loops times, or endlessly with the loops 0, and then io.EOF is returned.
+// The frames not matching the BPF filter are skipped as if they were filtered in the kernel.
+type offlineSource struct {
+	// received is placed first to be 64-bit aligned for the atomic operations
+	received uint64
+
+	path  string
+	speed float64
+	loops uint
+	bpf   *pcap.BPF
+
+	// mu guards the file reopened for the loops from Close by the other goroutine
+	mu     sync.Mutex
+	f      *os.File
+	closed bool
+	r      packetReader
+	n      uint
+
+	// The timestamps of the later passes follow the last frame of the previous pass.
+	passFirst time.Time
+	passStart time.Time
+	last      time.Time
+
+	// The frames are paced from the first frame read at start.
+	first time.Time
+	start time.Time
+}
+
+func openOffline(path string, speed float64, loops uint, filter string) (*offlineSource, error) {
+	o := &offlineSource{path: path, speed: speed, loops: loops}
+	if filter != "" {
+		bpf, err := pcap.NewBPF(layers.LinkTypeEthernet, snapshotLen, filter)
+		if err != nil {
+			return nil, err
+		}
+		o.bpf = bpf
+	}
+
+	if err := o.open(); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *offlineSource) open() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return os.ErrClosed
+	}
+	if o.f != nil {
+		o.f.Close()
+	}
+
+	f, err := os.Open(o.path)
+	if err != nil {
+		return err
+	}
+	o.f = f
+
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", o.path, err)
+	}
+
+	if bytes.Equal(magic, pcapngMagic) {
+		o.r, err = pcapgo.NewNgReader(br, pcapgo.DefaultNgReaderOptions)
+	} else {
+		o.r, err = pcapgo.NewReader(br)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", o.path, err)
+	}
+
+	o.passFirst = time.Time{}
+	return nil
+}
+
+func (o *offlineSource) ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	for {
+		data, ci, err := o.r.ZeroCopyReadPacketData()
+		if err == io.EOF {
+			o.n++
+			if o.n == o.loops || o.last.IsZero() {
+				return nil, ci, io.EOF
+			}
+
+			if err := o.open(); err != nil {
+				return nil, ci, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, ci, err
+		}
+
+		if o.bpf != nil && !o.bpf.Matches(ci, data) {
+			continue
+		}
+		atomic.AddUint64(&o.received, 1)
+
+		if o.passFirst.IsZero() {
+			o.passFirst = ci.Timestamp
+			o.passStart = ci.Timestamp
+			if !o.last.IsZero() {
+				o.passStart = o.last
+			}
+		}
+		ci.Timestamp = o.passStart.Add(ci.Timestamp.Sub(o.passFirst))
+		o.last = ci.Timestamp
+
+		if o.first.IsZero() {
+			o.first = ci.Timestamp
+			o.start = time.Now()
+		}
+
+		if o.speed == 0 {
+			ci.Timestamp = time.Now()
+			return data, ci, nil
+		}
+
+		at := o.start.Add(time.Duration(float64(ci.Timestamp.Sub(o.first)) / o.speed))
+		time.Sleep(time.Until(at))
+		ci.Timestamp = at
+
+		return data, ci, nil
+	}
+}
+
+func (o *offlineSource) CaptureStats() (captureStats, error) {
+	return captureStats{Received: atomic.LoadUint64(&o.received)}, nil
+}
+
+func (o *offlineSource) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.closed {
+		o.closed = true
+		o.f.Close()
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// writePcap writes the frames a second apart from the timestamp in the past.
+func writePcap(t *testing.T, frames [][]byte) string {
+	path := filepath.Join(t.TempDir(), "test.pcap")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal("Failed to create file:", err)
+	}
+	defer f.Close()
+
+	w := pcapgo.NewWriter(f)
+	if err := w.WriteFileHeader(snapshotLen, layers.LinkTypeEthernet); err != nil {
+		t.Fatal("Failed to write file header:", err)
+	}
+
+	ts := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, data := range frames {
+		ci := gopacket.CaptureInfo{Timestamp: ts.Add(time.Duration(i) * time.Second), CaptureLength: len(data), Length: len(data)}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal("Failed to write packet:", err)
+		}
+	}
+	return path
+}
+
+// readOffline returns the timestamps of the frames read until the end.
+func readOffline(t *testing.T, o *offlineSource) []time.Time {
+	var timestamps []time.Time
+	for {
+		_, ci, err := o.ZeroCopyReadPacketData()
+		if err == io.EOF {
+			return timestamps
+		}
+		if err != nil {
+			t.Fatal("Failed to read packet:", err)
+		}
+		timestamps = append(timestamps, ci.Timestamp)
+	}
+}
+
+func TestOfflineTimestamps(t *testing.T) {
+	frames := testFrames(t)
+	path := writePcap(t, [][]byte{frames["EoMPLS"], frames["EoMPLSCW"], frames["VXLAN"]})
+
+	// The frames a second apart are paced 1ms apart, and the second pass follows the last frame of the first
+	o, err := openOffline(path, 1000, 2, "")
+	if err != nil {
+		t.Fatal("Failed to open file:", err)
+	}
+	defer o.Close()
+
+	before := time.Now()
+	timestamps := readOffline(t, o)
+	after := time.Now()
+
+	if len(timestamps) != 6 {
+		t.Fatalf("The frames should be read '6' times, but was '%d'", len(timestamps))
+	}
+	if timestamps[0].Before(before) || timestamps[0].After(after) {
+		t.Errorf("The timestamp should be between '%v' and '%v', but was '%v'", before, after, timestamps[0])
+	}
+
+	for i, offset := range []int{0, 1, 2, 2, 3, 4} {
+		if d := timestamps[i].Sub(timestamps[0]); d != time.Duration(offset)*time.Millisecond {
+			t.Errorf("The frame %d should be '%dms' after the first, but was '%v'", i, offset, d)
+		}
+	}
+}
+
+func TestOfflineTimestampsNoWait(t *testing.T) {
+	frames := testFrames(t)
+	path := writePcap(t, [][]byte{frames["EoMPLS"], frames["EoMPLSCW"]})
+
+	o, err := openOffline(path, 0, 3, "")
+	if err != nil {
+		t.Fatal("Failed to open file:", err)
+	}
+	defer o.Close()
+
+	before := time.Now()
+	timestamps := readOffline(t, o)
+	after := time.Now()
+
+	if len(timestamps) != 6 {
+		t.Fatalf("The frames should be read '6' times, but was '%d'", len(timestamps))
+	}
+	for i, ts := range timestamps {
+		if ts.Before(before) || ts.After(after) {
+			t.Errorf("The timestamp of the frame %d should be between '%v' and '%v', but was '%v'", i, before, after, ts)
+		}
+		if i > 0 && ts.Before(timestamps[i-1]) {
+			t.Errorf("The timestamp of the frame %d should not go back, but was '%v'", i, ts)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/haccht/vplsbh/pkg/grpc"
+	"github.com/haccht/vplsbh/resolver"
+)
+
+// unknownMarker is the domain and the remote of the frames published with the label not found by the resolver.
+const unknownMarker = "unknown"
+
+var unknownLabelInfo = &resolver.Info{Domain: unknownMarker, Remote: unknownMarker}
+
+const (
+	// maxUnknownLabels bounds the registry of each decoder against the frames with the random labels.
+	maxUnknownLabels = 4096
+	// maxOuterMACs bounds the outer MACs recorded per label.
+	maxOuterMACs = 8
+	// unknownLabelTTL is the time to forget the label not seen any more.
+	unknownLabelTTL = 24 * time.Hour
+)
+
+// unknownLabel is the label, or the VNI, seen without the mapping by the resolver.
+type unknownLabel struct {
+	firstSeen, lastSeen time.Time
+	packets             uint64
+	outerMACs           []string
+
+	// updated is when the label was seen last, which differs from lastSeen reading the file.
+	updated time.Time
+}
+
+// unknownRegistry keeps the labels seen without the mapping to find the missing provisioning.
+// The labels are keyed by the cache key, which is uint32 or labelKey for the label and vniKey for the VNI.
+type unknownRegistry struct {
+	sync.Mutex
+	labels map[interface{}]*unknownLabel
+	pruned time.Time
+}
+
+func newUnknownRegistry() *unknownRegistry {
+	return &unknownRegistry{labels: make(map[interface{}]*unknownLabel)}
+}
+
+func (r *unknownRegistry) record(k interface{}, ts time.Time, mac net.HardwareAddr) {
+	r.Lock()
+	defer r.Unlock()
+
+	u, ok := r.labels[k]
+	if !ok {
+		// The stale labels are pruned at most once a minute when the registry is full
+		if len(r.labels) >= maxUnknownLabels && time.Since(r.pruned) > time.Minute {
+			r.prune()
+		}
+		if len(r.labels) >= maxUnknownLabels {
+			return
+		}
+
+		u = &unknownLabel{firstSeen: ts}
+		r.labels[k] = u
+	}
+
+	u.lastSeen = ts
+	u.updated = time.Now()
+	u.packets++
+
+	if mac == nil || len(u.outerMACs) >= maxOuterMACs {
+		return
+	}
+	for _, m := range u.outerMACs {
+		if m == string(mac) {
+			return
+		}
+	}
+	u.outerMACs = append(u.outerMACs, string(mac))
+}
+
+// prune forgets the labels not seen any more.
+func (r *unknownRegistry) prune() {
+	now := time.Now()
+	for k, u := range r.labels {
+		if now.Sub(u.updated) > unknownLabelTTL {
+			delete(r.labels, k)
+		}
+	}
+	r.pruned = now
+}
+
+// list returns the labels in the registry.
+func (r *unknownRegistry) list() []*pb.UnknownLabel {
+	r.Lock()
+	defer r.Unlock()
+
+	r.prune()
+
+	var labels []*pb.UnknownLabel
+	for k, u := range r.labels {
+		l := &pb.UnknownLabel{
+			Firstseen: timestamppb.New(u.firstSeen),
+			Lastseen:  timestamppb.New(u.lastSeen),
+			Packets:   u.packets,
+		}
+		for _, m := range u.outerMACs {
+			l.Outermacs = append(l.Outermacs, net.HardwareAddr(m).String())
+		}
+
+		switch k := k.(type) {
+		case vniKey:
+			l.Vni = uint32(k)
+		case uint32:
+			l.Label = k
+		case labelKey:
+			l.Label, l.Context = k.label, k.context
+		}
+		labels = append(labels, l)
+	}
+
+	return labels
+}
+
+func (s *streamer) Unknown(ctx context.Context, req *pb.UnknownRequest) (*pb.UnknownReply, error) {
+	// A label is recorded by a single decoder, as the frames of the label are dispatched to the same worker
+	var labels []*pb.UnknownLabel
+	for _, d := range s.allDecoders() {
+		labels = append(labels, d.unknowns.list()...)
+	}
+
+	// Sort the labels by the VNI, the context and the label
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Vni != labels[j].Vni {
+			return labels[i].Vni < labels[j].Vni
+		}
+		if labels[i].Context != labels[j].Context {
+			return labels[i].Context < labels[j].Context
+		}
+		return labels[i].Label < labels[j].Label
+	})
+	return &pb.UnknownReply{Labels: labels}, nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	pb "github.com/haccht/vplsbh/pkg/grpc"
+)
+
+func TestUnknownRegistry(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	macs := make([]net.HardwareAddr, maxOuterMACs+2)
+	for i := range macs {
+		macs[i] = net.HardwareAddr{0xcc, 0x15, 0x14, 0x64, 0x00, byte(i)}
+	}
+
+	type record struct {
+		k   interface{}
+		ts  time.Time
+		mac net.HardwareAddr
+	}
+	tests := []struct {
+		name      string
+		records   []record
+		k         interface{}
+		packets   uint64
+		firstSeen time.Time
+		lastSeen  time.Time
+		outerMACs int
+	}{
+		{
+			name:    "Label",
+			records: []record{{uint32(100), ts, macs[0]}},
+			k:       uint32(100), packets: 1, firstSeen: ts, lastSeen: ts, outerMACs: 1,
+		},
+		{
+			name: "Counters",
+			records: []record{
+				{labelKey{"eth0", 100}, ts, macs[0]},
+				{labelKey{"eth0", 100}, ts.Add(time.Second), macs[0]},
+				{labelKey{"eth0", 100}, ts.Add(2 * time.Second), macs[1]},
+			},
+			k: labelKey{"eth0", 100}, packets: 3, firstSeen: ts, lastSeen: ts.Add(2 * time.Second), outerMACs: 2,
+		},
+		{
+			name: "OuterMACs",
+			records: func() []record {
+				var rs []record
+				for _, mac := range macs {
+					rs = append(rs, record{vniKey(5000), ts, mac})
+				}
+				return append(rs, record{vniKey(5000), ts, nil})
+			}(),
+			k: vniKey(5000), packets: uint64(len(macs) + 1), firstSeen: ts, lastSeen: ts, outerMACs: maxOuterMACs,
+		},
+	}
+
+	for _, tt := range tests {
+		r := newUnknownRegistry()
+		for _, rec := range tt.records {
+			r.record(rec.k, rec.ts, rec.mac)
+		}
+
+		u, ok := r.labels[tt.k]
+		if !ok {
+			t.Fatalf("The %s '%v' should be registered", tt.name, tt.k)
+		}
+		if u.packets != tt.packets {
+			t.Errorf("The packets of the %s should be '%d', but was '%d'", tt.name, tt.packets, u.packets)
+		}
+		if !u.firstSeen.Equal(tt.firstSeen) || !u.lastSeen.Equal(tt.lastSeen) {
+			t.Errorf("The %s should be seen from '%v' to '%v', but was from '%v' to '%v'", tt.name, tt.firstSeen, tt.lastSeen, u.firstSeen, u.lastSeen)
+		}
+		if len(u.outerMACs) != tt.outerMACs {
+			t.Errorf("The outer MACs of the %s should be '%d', but was '%d'", tt.name, tt.outerMACs, len(u.outerMACs))
+		}
+	}
+}
+
+func TestUnknownRegistryLimit(t *testing.T) {
+	r := newUnknownRegistry()
+	for i := 0; i < maxUnknownLabels+1; i++ {
+		r.record(uint32(i), time.Now(), nil)
+	}
+
+	if len(r.labels) != maxUnknownLabels {
+		t.Errorf("The labels should be limited to '%d', but was '%d'", maxUnknownLabels, len(r.labels))
+	}
+	if _, ok := r.labels[uint32(maxUnknownLabels)]; ok {
+		t.Error("The label over the limit should not be registered")
+	}
+
+	// The labels not seen any more are pruned for the new label a minute after the last pruning
+	for _, u := range r.labels {
+		u.updated = time.Now().Add(-unknownLabelTTL - time.Minute)
+	}
+	r.labels[uint32(0)].updated = time.Now()
+	r.pruned = r.pruned.Add(-time.Minute)
+
+	r.record(uint32(maxUnknownLabels), time.Now(), nil)
+	if len(r.labels) != 2 {
+		t.Errorf("The stale labels should be pruned, but was '%d' labels", len(r.labels))
+	}
+	if _, ok := r.labels[uint32(maxUnknownLabels)]; !ok {
+		t.Error("The new label should be registered after the stale labels are pruned")
+	}
+
+	// The stale labels are not listed
+	r.labels[uint32(0)].updated = time.Now().Add(-unknownLabelTTL - time.Minute)
+	if labels := r.list(); len(labels) != 1 || labels[0].Label != uint32(maxUnknownLabels) {
+		t.Errorf("The stale label should not be listed, but was '%v'", labels)
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	s := newTestStreamer(t, "--label-context", "interface")
+	ds := []*decoder{s.newDecoder(false), s.newDecoder(false)}
+
+	frame := func(label uint32) []byte {
+		return serializeFrame(t,
+			&layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: layers.EthernetTypeMPLSUnicast},
+			&layers.MPLS{Label: label, StackBottom: true, TTL: 255},
+			&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast, EthernetType: layers.EthernetTypeARP},
+			gopacket.Payload(make([]byte, 46)),
+		)
+	}
+	vxlan := serializeFrame(t,
+		&layers.Ethernet{SrcMAC: testOuterSrcMAC, DstMAC: testOuterDstMAC, EthernetType: layers.EthernetTypeIPv4},
+		&layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolUDP, SrcIP: net.IP{192, 0, 2, 1}, DstIP: net.IP{192, 0, 2, 2}},
+		&layers.UDP{SrcPort: 49152, DstPort: 4789},
+		&layers.VXLAN{ValidIDFlag: true, VNI: 6000},
+		&layers.Ethernet{SrcMAC: testInnerSrcMAC, DstMAC: testBroadcast, EthernetType: layers.EthernetTypeARP},
+		gopacket.Payload(make([]byte, 46)),
+	)
+
+	// The labels are recorded by the decoders which the frames are dispatched to
+	inputs := []struct {
+		d     *decoder
+		data  []byte
+		iface string
+	}{
+		{ds[0], frame(901), "eth1"},
+		{ds[1], frame(902), "eth0"},
+		{ds[0], frame(900), "eth1"},
+		{ds[1], vxlan, "eth0"},
+		{ds[0], frame(900), "eth0"},
+	}
+	for _, in := range inputs {
+		ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(in.data), Length: len(in.data)}
+		if _, err := in.d.decode(in.data, ci, in.iface); err != errUnknownLabel {
+			t.Fatalf("The frame should be rejected as unknown, but was '%v'", err)
+		}
+	}
+
+	reply, err := s.Unknown(context.Background(), &pb.UnknownRequest{})
+	if err != nil {
+		t.Fatal("Failed to list the unknown labels:", err)
+	}
+
+	expected := []struct {
+		vni     uint32
+		context string
+		label   uint32
+	}{
+		{0, "eth0", 900},
+		{0, "eth0", 902},
+		{0, "eth1", 900},
+		{0, "eth1", 901},
+		{6000, "", 0},
+	}
+	if len(reply.Labels) != len(expected) {
+		t.Fatalf("The unknown labels should be '%d', but was '%d'", len(expected), len(reply.Labels))
+	}
+	for i, e := range expected {
+		l := reply.Labels[i]
+		if l.Vni != e.vni || l.Context != e.context || l.Label != e.label {
+			t.Errorf("The unknown label %d should be '%v', but was '%v'", i, e, l)
+		}
+		if l.Packets != 1 || len(l.Outermacs) != 1 || l.Outermacs[0] != testOuterSrcMAC.String() {
+			t.Errorf("The unknown label %d should be seen once from '%v', but was '%v'", i, testOuterSrcMAC, l)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package l2vpn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+var benchPackets = []struct {
+	name  string
+	data  []byte
+	first gopacket.LayerType
+}{
+	{"EoMPLS", testPacket1, layers.LayerTypeEthernet},
+	{"EoMPLSCW", testPacket2, LayerTypePWMCW},
+	{"LabelStack", testPacket3, LayerTypePWMCW},
+	{"EntropyLabel", testPacket4, LayerTypePWMCW},
+	{"PWACH", testPacket5, LayerTypePWACH},
+}
+
+// BenchmarkDecode decodes the test packets from the outer Ethernet to the inner Ethernet or the PW-ACH,
+// reusing the layers and the parsers as bumstream does.
+func BenchmarkDecode(b *testing.B) {
+	for _, bp := range benchPackets {
+		b.Run(bp.name, func(b *testing.B) {
+			var encap Encap
+			var vpls VPLS
+			var pwmcw PWMCW
+			var pwach PWACH
+			var eth layers.Ethernet
+
+			decoded := make([]gopacket.LayerType, 0, 3)
+
+			stack := gopacket.NewDecodingLayerParser(layers.LayerTypeMPLS, &vpls)
+			stack.IgnoreUnsupported = true
+
+			payload := gopacket.NewDecodingLayerParser(bp.first, &pwmcw, &pwach, &eth)
+			payload.IgnoreUnsupported = true
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(bp.data)))
+			b.ResetTimer()
+
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				data, err := encap.DecodeFromBytes(bp.data)
+				if err != nil {
+					b.Fatal("Failed to decode encapsulation:", err)
+				}
+
+				if err := stack.DecodeLayers(data, &decoded); err != nil {
+					b.Fatal("Failed to decode label stack:", err)
+				}
+
+				if err := payload.DecodeLayers(vpls.Payload, &decoded); err != nil {
+					b.Fatal("Failed to decode PW payload:", err)
+				}
+			}
+
+			b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "pkts/s")
+		})
+	}
+}
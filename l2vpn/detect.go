@@ -0,0 +1,74 @@
+package l2vpn
+
+import (
+	"encoding/binary"
+)
+
+// ControlWordDetector learns whether a PW carries the control word from the PW payload.
+// An Ethernet frame without the control word may also start with the nibble 0,
+// so the PW is assumed to have the control word until a payload tells otherwise.
+// The learned state follows the last payload which tells, as the PW may be signaled again with or without it.
+type ControlWordDetector struct {
+	absent bool
+}
+
+// Detect tells whether the payload starts with the control word.
+func (d *ControlWordDetector) Detect(payload []byte) bool {
+	if cw, ok := guessControlWord(payload); ok {
+		d.absent = !cw
+	}
+	return !d.absent
+}
+
+// Absent tells whether the PW has been learned to have no control word.
+func (d *ControlWordDetector) Absent() bool {
+	return d.absent
+}
+
+// Reset forgets the learned state.
+func (d *ControlWordDetector) Reset() {
+	d.absent = false
+}
+
+// guessControlWord tells whether the payload starts with the control word, and whether the payload tells it at all.
+func guessControlWord(payload []byte) (cw, ok bool) {
+	if len(payload) == 0 {
+		return false, false
+	}
+
+	switch payload[0] >> 4 {
+	case 0:
+	case 1:
+		// PW Associated Channel Header or a destination MAC, which does not tell anything
+		return false, false
+	default:
+		return false, true
+	}
+
+	// The reserved bits following the nibble 0 are zero in the control word of Ethernet PW (RFC 4448),
+	// while they are not in the destination MAC such as 01:00:5e of IPv4 multicast.
+	if payload[0] != 0 {
+		return false, true
+	}
+
+	// Otherwise the payload tells only when it looks like the Ethernet frame either with or without the control word
+	with, without := len(payload) >= 4 && isEthernetLike(payload[4:]), isEthernetLike(payload)
+	if with == without {
+		return false, false
+	}
+	return with, true
+}
+
+// isEthernetLike tells whether the data looks like the Ethernet frame by the unicast source MAC and the EtherType.
+func isEthernetLike(data []byte) bool {
+	if len(data) < 14 {
+		return false
+	}
+
+	if data[6]&0x01 != 0 {
+		return false
+	}
+
+	typ := binary.BigEndian.Uint16(data[12:14])
+	return typ >= 0x0600 || int(typ) <= len(data)-14
+}
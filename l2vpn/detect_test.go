@@ -0,0 +1,49 @@
+package l2vpn
+
+import (
+	"testing"
+)
+
+func TestControlWordDetector(t *testing.T) {
+	var d ControlWordDetector
+
+	if !d.Detect(testPacket2[18:]) {
+		t.Error("The payload starting with 0 should be assumed to have the control word")
+	}
+
+	if d.Detect([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}) {
+		t.Error("The payload starting with 0xf should not have the control word")
+	}
+
+	if d.Detect(testPacket1[18:]) || !d.Absent() {
+		t.Error("The PW should be learned to have no control word")
+	}
+}
+
+func TestControlWordDetectorMulticast(t *testing.T) {
+	var d ControlWordDetector
+
+	// IPv4 multicast frame without the control word, whose destination MAC starts with the nibble 0
+	multicast := []byte{
+		0x01, 0x00, 0x5e, 0x00, 0x00, 0x05, 0x00, 0x00, 0x5e, 0x00, 0x53, 0x01, 0x08, 0x00,
+		0x45, 0xc0, 0x00, 0x40, 0x00, 0x00, 0x00, 0x00, 0x01, 0x59, 0x00, 0x00,
+	}
+	if d.Detect(multicast) || !d.Absent() {
+		t.Error("The payload starting with the multicast MAC should not have the control word")
+	}
+
+	// Broadcast frame after the control word, which can not be the Ethernet frame without it
+	broadcast := []byte{
+		0x00, 0x00, 0x00, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x5e, 0x00, 0x53, 0x01,
+		0x08, 0x06, 0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01,
+	}
+	if !d.Detect(broadcast) || d.Absent() {
+		t.Error("The PW should be learned to have the control word again")
+	}
+
+	d.Detect(multicast)
+	d.Reset()
+	if d.Absent() {
+		t.Error("The learned state should be forgotten by Reset")
+	}
+}
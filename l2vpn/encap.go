@@ -0,0 +1,190 @@
+package l2vpn
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// UDPPortMPLS is the destination port of MPLS-over-UDP (RFC 7510).
+const UDPPortMPLS layers.UDPPort = 6635
+
+// UDPPortVXLAN is the destination port of VXLAN (RFC 7348).
+const UDPPortVXLAN layers.UDPPort = 4789
+
+// EncapType is the encapsulation which carries the mirrored MPLS frame to the collector.
+type EncapType uint8
+
+const (
+	EncapTypeEthernet EncapType = iota
+	EncapTypeGRE
+	EncapTypeUDP
+	EncapTypeERSPAN
+	EncapTypeVXLAN
+)
+
+func (t EncapType) String() string {
+	switch t {
+	case EncapTypeEthernet:
+		return "Ethernet"
+	case EncapTypeGRE:
+		return "MPLS-over-GRE"
+	case EncapTypeUDP:
+		return "MPLS-over-UDP"
+	case EncapTypeERSPAN:
+		return "ERSPAN"
+	case EncapTypeVXLAN:
+		return "VXLAN"
+	default:
+		return "Unknown"
+	}
+}
+
+// Encap decodes the encapsulation in front of the MPLS label stack of a mirrored frame.
+// It is an Ethernet frame with optional 802.1Q/QinQ tags directly followed by MPLS,
+// or by MPLS-over-GRE (RFC 4023) or MPLS-over-UDP (RFC 7510) in IPv4 or IPv6.
+// ERSPAN Type II and III are decapsulated and the mirrored Ethernet frame is decoded in turn.
+// VXLAN (RFC 7348) carries the BUM frame itself in place of MPLS.
+type Encap struct {
+	Type   EncapType
+	SrcMAC net.HardwareAddr
+	DstMAC net.HardwareAddr
+
+	// VLANs holds the VLAN identifiers of the outer tags from the outermost.
+	VLANs []uint16
+
+	// SrcIP and DstIP are the tunnel endpoints for MPLS-over-GRE, MPLS-over-UDP, ERSPAN and VXLAN.
+	SrcIP net.IP
+	DstIP net.IP
+
+	// SessionID, Timestamp and Granularity are taken from the ERSPAN header.
+	// Timestamp and Granularity are available only with ERSPAN Type III.
+	ERSPANVersion uint8
+	SessionID     uint16
+	Timestamp     uint32
+	Granularity   uint8
+
+	// VNI is the VXLAN Network Identifier.
+	VNI uint32
+
+	eth   layers.Ethernet
+	dot1q layers.Dot1Q
+	ip4   layers.IPv4
+	ip6   layers.IPv6
+	gre   layers.GRE
+	udp   layers.UDP
+
+	erspan2 layers.ERSPANII
+	erspan3 ERSPANIII
+	vxlan   layers.VXLAN
+}
+
+// malformed wraps the error returned by the layers of gopacket.
+func malformed(typ gopacket.LayerType, err error) error {
+	return &DecodeError{Layer: typ.String(), Err: fmt.Errorf("%w: %v", ErrMalformed, err)}
+}
+
+// DecodeFromBytes decodes the encapsulation and returns the MPLS label stack following it,
+// or the inner Ethernet frame for VXLAN.
+func (e *Encap) DecodeFromBytes(data []byte) ([]byte, error) {
+	e.Type = EncapTypeEthernet
+	e.VLANs = e.VLANs[:0]
+	e.SrcIP, e.DstIP = nil, nil
+	e.ERSPANVersion, e.SessionID, e.Timestamp, e.Granularity = 0, 0, 0, 0
+	e.VNI = 0
+
+	df := gopacket.NilDecodeFeedback
+	typ := layers.LayerTypeEthernet
+
+	for {
+		switch typ {
+		case layers.LayerTypeEthernet:
+			if err := e.eth.DecodeFromBytes(data, df); err != nil {
+				return nil, malformed(typ, err)
+			}
+			e.SrcMAC, e.DstMAC = e.eth.SrcMAC, e.eth.DstMAC
+			typ, data = e.eth.NextLayerType(), e.eth.Payload
+		case layers.LayerTypeDot1Q:
+			if err := e.dot1q.DecodeFromBytes(data, df); err != nil {
+				return nil, malformed(typ, err)
+			}
+			e.VLANs = append(e.VLANs, e.dot1q.VLANIdentifier)
+			typ, data = e.dot1q.NextLayerType(), e.dot1q.Payload
+		case layers.LayerTypeIPv4:
+			if err := e.ip4.DecodeFromBytes(data, df); err != nil {
+				return nil, malformed(typ, err)
+			}
+			e.SrcIP, e.DstIP = e.ip4.SrcIP, e.ip4.DstIP
+			typ, data = e.ip4.NextLayerType(), e.ip4.Payload
+		case layers.LayerTypeIPv6:
+			if err := e.ip6.DecodeFromBytes(data, df); err != nil {
+				return nil, malformed(typ, err)
+			}
+			e.SrcIP, e.DstIP = e.ip6.SrcIP, e.ip6.DstIP
+			typ, data = e.ip6.NextLayerType(), e.ip6.Payload
+		case layers.LayerTypeGRE:
+			if err := e.gre.DecodeFromBytes(data, df); err != nil {
+				return nil, malformed(typ, err)
+			}
+			e.Type = EncapTypeGRE
+			typ, data = e.gre.NextLayerType(), e.gre.Payload
+
+			// ERSPAN Type III is told by its protocol type, which is not registered to gopacket
+			if e.gre.Protocol == EthernetTypeERSPANIII {
+				typ = LayerTypeERSPANIII
+			}
+		case layers.LayerTypeUDP:
+			if err := e.udp.DecodeFromBytes(data, df); err != nil {
+				return nil, malformed(typ, err)
+			}
+			switch e.udp.DstPort {
+			case UDPPortMPLS:
+				e.Type = EncapTypeUDP
+				typ, data = layers.LayerTypeMPLS, e.udp.Payload
+			case UDPPortVXLAN:
+				typ, data = layers.LayerTypeVXLAN, e.udp.Payload
+			default:
+				return nil, &DecodeError{Layer: "UDP", Err: ErrNoMPLS}
+			}
+		case layers.LayerTypeVXLAN:
+			if err := e.vxlan.DecodeFromBytes(data, df); err != nil {
+				return nil, malformed(typ, err)
+			}
+			e.Type = EncapTypeVXLAN
+			e.VNI = e.vxlan.VNI
+			return e.vxlan.Payload, nil
+		case layers.LayerTypeERSPANII:
+			if len(data) < 8 {
+				return nil, &DecodeError{Layer: "ERSPANII", Err: ErrTruncated}
+			}
+			if err := e.erspan2.DecodeFromBytes(data, df); err != nil {
+				return nil, malformed(typ, err)
+			}
+			e.Type = EncapTypeERSPAN
+			e.ERSPANVersion = e.erspan2.Version
+			e.SessionID = e.erspan2.SessionID
+
+			// The VLANs of the collector link are replaced with those of the mirrored frame
+			e.VLANs = e.VLANs[:0]
+			typ, data = e.erspan2.NextLayerType(), e.erspan2.Payload
+		case LayerTypeERSPANIII:
+			if err := e.erspan3.DecodeFromBytes(data, df); err != nil {
+				return nil, err
+			}
+			e.Type = EncapTypeERSPAN
+			e.ERSPANVersion = e.erspan3.Version
+			e.SessionID = e.erspan3.SessionID
+			e.Timestamp = e.erspan3.Timestamp
+			e.Granularity = e.erspan3.Granularity
+
+			e.VLANs = e.VLANs[:0]
+			typ, data = e.erspan3.NextLayerType(), e.erspan3.Payload
+		case layers.LayerTypeMPLS:
+			return data, nil
+		default:
+			return nil, &DecodeError{Layer: typ.String(), Err: ErrNoMPLS}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package l2vpn
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func serializeEncap(t *testing.T, mpls []byte, l ...gopacket.SerializableLayer) []byte {
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true}
+
+	l = append(l, gopacket.Payload(mpls))
+	if err := gopacket.SerializeLayers(buf, opts, l...); err != nil {
+		t.Fatal("Failed to serialize packet:", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestEncap(t *testing.T) {
+	mpls := testPacket3[14:]
+	srcMAC, _ := net.ParseMAC("cc:15:14:64:00:00")
+	dstMAC, _ := net.ParseMAC("cc:13:14:64:00:01")
+	srcIP, dstIP := net.IPv4(192, 0, 2, 1), net.IPv4(192, 0, 2, 2)
+
+	tests := []struct {
+		name  string
+		data  []byte
+		typ   EncapType
+		vlans []uint16
+	}{
+		{
+			name: "Ethernet",
+			data: testPacket3,
+			typ:  EncapTypeEthernet,
+		},
+		{
+			name: "QinQ",
+			data: serializeEncap(t, mpls,
+				&layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeQinQ},
+				&layers.Dot1Q{VLANIdentifier: 100, Type: layers.EthernetTypeDot1Q},
+				&layers.Dot1Q{VLANIdentifier: 200, Type: layers.EthernetTypeMPLSUnicast},
+			),
+			typ:   EncapTypeEthernet,
+			vlans: []uint16{100, 200},
+		},
+		{
+			name: "MPLS-over-GRE",
+			data: serializeEncap(t, mpls,
+				&layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv4},
+				&layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolGRE, SrcIP: srcIP, DstIP: dstIP},
+				&layers.GRE{Protocol: layers.EthernetTypeMPLSUnicast},
+			),
+			typ: EncapTypeGRE,
+		},
+		{
+			name: "MPLS-over-UDP",
+			data: serializeEncap(t, mpls,
+				&layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv4},
+				&layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolUDP, SrcIP: srcIP, DstIP: dstIP},
+				&layers.UDP{SrcPort: 49152, DstPort: UDPPortMPLS},
+			),
+			typ: EncapTypeUDP,
+		},
+		{
+			name: "ERSPAN Type II",
+			data: serializeEncap(t, testPacket3,
+				&layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv4},
+				&layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolGRE, SrcIP: srcIP, DstIP: dstIP},
+				&layers.GRE{SeqPresent: true, Seq: 1, Protocol: layers.EthernetTypeERSPAN},
+				&layers.ERSPANII{Version: layers.ERSPANIIVersion, SessionID: 100},
+			),
+			typ: EncapTypeERSPAN,
+		},
+		{
+			name: "ERSPAN Type III",
+			data: serializeEncap(t, testPacket3,
+				&layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv4},
+				&layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolGRE, SrcIP: srcIP, DstIP: dstIP},
+				&layers.GRE{SeqPresent: true, Seq: 1, Protocol: EthernetTypeERSPANIII},
+				&ERSPANIII{Version: 2, SessionID: 100, Timestamp: 123456, Granularity: 3, Platform: make([]byte, 8)},
+			),
+			typ: EncapTypeERSPAN,
+		},
+	}
+
+	for _, tt := range tests {
+		var e Encap
+		payload, err := e.DecodeFromBytes(tt.data)
+		if err != nil {
+			t.Errorf("%s: Failed to decode encapsulation: %v", tt.name, err)
+			continue
+		}
+
+		if !bytes.Equal(payload, mpls) {
+			t.Errorf("%s: The payload should be the MPLS label stack, but was %x", tt.name, payload)
+		}
+
+		if e.Type != tt.typ {
+			t.Errorf("%s: The encapsulation should be %s, but was %s", tt.name, tt.typ, e.Type)
+		}
+
+		if fmt.Sprint(e.VLANs) != fmt.Sprint(tt.vlans) {
+			t.Errorf("%s: The VLANs should be %v, but was %v", tt.name, tt.vlans, e.VLANs)
+		}
+
+		if e.Type == EncapTypeERSPAN && e.SessionID != 100 {
+			t.Errorf("%s: The ERSPAN session should be 100, but was %d", tt.name, e.SessionID)
+		}
+
+		if e.Type == EncapTypeERSPAN && e.ERSPANVersion == 2 && (e.Timestamp != 123456 || e.Granularity != 3) {
+			t.Errorf("%s: The ERSPAN timestamp should be 123456 with granularity 3, but was %d with %d", tt.name, e.Timestamp, e.Granularity)
+		}
+
+		if e.Type != EncapTypeEthernet && !e.SrcIP.Equal(srcIP) {
+			t.Errorf("%s: The tunnel source should be %s, but was %s", tt.name, srcIP, e.SrcIP)
+		}
+	}
+}
+
+func TestEncapVXLAN(t *testing.T) {
+	inner := testPacket2[22:]
+	srcMAC, _ := net.ParseMAC("cc:15:14:64:00:00")
+	dstMAC, _ := net.ParseMAC("cc:13:14:64:00:01")
+	srcIP, dstIP := net.IPv4(192, 0, 2, 1), net.IPv4(192, 0, 2, 2)
+
+	data := serializeEncap(t, inner,
+		&layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv4},
+		&layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: layers.IPProtocolUDP, SrcIP: srcIP, DstIP: dstIP},
+		&layers.UDP{SrcPort: 49152, DstPort: UDPPortVXLAN},
+		&layers.VXLAN{ValidIDFlag: true, VNI: 10100},
+	)
+
+	var e Encap
+	payload, err := e.DecodeFromBytes(data)
+	if err != nil {
+		t.Fatal("Failed to decode encapsulation:", err)
+	}
+
+	if e.Type != EncapTypeVXLAN || e.VNI != 10100 || !e.SrcIP.Equal(srcIP) {
+		t.Errorf("The encapsulation should be VXLAN with VNI 10100 from %s, but was %s with VNI %d from %s", srcIP, e.Type, e.VNI, e.SrcIP)
+	}
+
+	if !bytes.Equal(payload, inner) {
+		t.Errorf("The payload should be the inner Ethernet frame, but was %x", payload)
+	}
+}
@@ -0,0 +1,31 @@
+package l2vpn
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTruncated          = errors.New("truncated")
+	ErrMalformed          = errors.New("malformed")
+	ErrNoMPLS             = errors.New("MPLS is missing")
+	ErrNoPWLabel          = errors.New("PW label is missing")
+	ErrNoFlowLabel        = errors.New("FAT flow label is missing")
+	ErrMissingControlWord = errors.New("control word is missing")
+	ErrMissingACH         = errors.New("associated channel header is missing")
+)
+
+// DecodeError is the error returned by the layers of this package.
+// Err is one of the errors above and can be tested with errors.Is.
+type DecodeError struct {
+	Layer string
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Layer, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
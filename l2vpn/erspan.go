@@ -0,0 +1,136 @@
+package l2vpn
+
+import (
+	"encoding/binary"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// EthernetTypeERSPANIII is the GRE protocol type of ERSPAN Type III.
+const EthernetTypeERSPANIII layers.EthernetType = 0x22eb
+
+var LayerTypeERSPANIII = gopacket.RegisterLayerType(2003, gopacket.LayerTypeMetadata{Name: "ERSPANIII", Decoder: gopacket.DecodeFunc(decodeERSPANIII)})
+
+// ERSPANIII is the ERSPAN Type III header.
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|  Ver  |          VLAN         | COS |BSO|T|     Session ID    |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|                          Timestamp                            |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|             SGT               |P|    FT   |   Hw ID   |D|Gra|O|
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|        Platform Specific SubHeader (8 octets, optional)       |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type ERSPANIII struct {
+	Version        uint8
+	VLANIdentifier uint16
+	CoS            uint8
+	BSO            uint8
+	IsTruncated    bool
+	SessionID      uint16
+	Timestamp      uint32
+	SGT            uint16
+	IsPDU          bool
+	FrameType      uint8
+	HardwareID     uint8
+	Egress         bool
+	Granularity    uint8
+	Platform       []byte
+	layers.BaseLayer
+}
+
+func (e *ERSPANIII) LayerType() gopacket.LayerType {
+	return LayerTypeERSPANIII
+}
+
+func (e *ERSPANIII) CanDecode() gopacket.LayerClass {
+	return LayerTypeERSPANIII
+}
+
+func (e *ERSPANIII) NextLayerType() gopacket.LayerType {
+	return layers.LayerTypeEthernet
+}
+
+func (e *ERSPANIII) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 12 {
+		return &DecodeError{Layer: "ERSPANIII", Err: ErrTruncated}
+	}
+
+	e.Version = data[0] >> 4
+	e.VLANIdentifier = binary.BigEndian.Uint16(data[0:2]) & 0x0FFF
+	e.CoS = data[2] >> 5
+	e.BSO = data[2] >> 3 & 0x3
+	e.IsTruncated = data[2]&0x4 != 0
+	e.SessionID = binary.BigEndian.Uint16(data[2:4]) & 0x03FF
+	e.Timestamp = binary.BigEndian.Uint32(data[4:8])
+	e.SGT = binary.BigEndian.Uint16(data[8:10])
+	e.IsPDU = data[10]&0x80 != 0
+	e.FrameType = data[10] >> 2 & 0x1F
+	e.HardwareID = (data[10]&0x3)<<4 | data[11]>>4
+	e.Egress = data[11]&0x8 != 0
+	e.Granularity = data[11] >> 1 & 0x3
+
+	length := 12
+	e.Platform = nil
+	if data[11]&0x1 != 0 {
+		if len(data) < 20 {
+			return &DecodeError{Layer: "ERSPANIII", Err: ErrTruncated}
+		}
+		e.Platform = data[12:20]
+		length = 20
+	}
+
+	e.BaseLayer = layers.BaseLayer{Contents: data[:length], Payload: data[length:]}
+	return nil
+}
+
+func (e *ERSPANIII) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	length := 12
+	if e.Platform != nil {
+		length = 20
+	}
+
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint16(bytes[0:], uint16(e.Version&0xF)<<12|e.VLANIdentifier&0x0FFF)
+	binary.BigEndian.PutUint16(bytes[2:], uint16(e.CoS&0x7)<<13|uint16(e.BSO&0x3)<<11|e.SessionID&0x03FF)
+	if e.IsTruncated {
+		bytes[2] |= 0x4
+	}
+	binary.BigEndian.PutUint32(bytes[4:], e.Timestamp)
+	binary.BigEndian.PutUint16(bytes[8:], e.SGT)
+
+	bytes[10] = (e.FrameType&0x1F)<<2 | e.HardwareID>>4&0x3
+	if e.IsPDU {
+		bytes[10] |= 0x80
+	}
+	bytes[11] = (e.HardwareID&0xF)<<4 | (e.Granularity&0x3)<<1
+	if e.Egress {
+		bytes[11] |= 0x8
+	}
+	if e.Platform != nil {
+		bytes[11] |= 0x1
+		copy(bytes[12:20], e.Platform)
+	}
+
+	return nil
+}
+
+func decodeERSPANIII(data []byte, p gopacket.PacketBuilder) error {
+	e := &ERSPANIII{}
+
+	err := e.DecodeFromBytes(data, p)
+	if err != nil {
+		return err
+	}
+
+	p.AddLayer(e)
+	return p.NextDecoder(layers.LayerTypeEthernet)
+}
@@ -0,0 +1,79 @@
+package l2vpn
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+var fuzzSeeds = [][]byte{testPacket1, testPacket2, testPacket3, testPacket4, testPacket5, testPacket6}
+
+func checkDecodeError(t *testing.T, err error) {
+	var de *DecodeError
+	if err != nil && !errors.As(err, &de) {
+		t.Errorf("The error should be DecodeError, but was %T: %v", err, err)
+	}
+}
+
+func FuzzEncap(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var e Encap
+		_, err := e.DecodeFromBytes(data)
+		checkDecodeError(t, err)
+	})
+}
+
+func FuzzVPLS(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed[14:], 0, false)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, index int, fat bool) {
+		vpls := VPLS{PWLabelIndex: index, FAT: fat}
+		err := vpls.DecodeFromBytes(data, gopacket.NilDecodeFeedback)
+		checkDecodeError(t, err)
+
+		if err == nil {
+			vpls.SplitESILabel()
+		}
+	})
+}
+
+func FuzzPWPayload(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed[18:])
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var cw PWMCW
+		checkDecodeError(t, cw.DecodeFromBytes(data, gopacket.NilDecodeFeedback))
+
+		var ach PWACH
+		checkDecodeError(t, ach.DecodeFromBytes(data, gopacket.NilDecodeFeedback))
+
+		var d ControlWordDetector
+		d.Detect(data)
+	})
+}
+
+func FuzzCustomerFrame(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v VLANTags
+		checkDecodeError(t, v.DecodeFromBytes(data))
+
+		var pbb PBB
+		checkDecodeError(t, pbb.DecodeFromBytes(data, gopacket.NilDecodeFeedback))
+
+		var erspan ERSPANIII
+		checkDecodeError(t, erspan.DecodeFromBytes(data, gopacket.NilDecodeFeedback))
+	})
+}
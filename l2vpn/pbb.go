@@ -0,0 +1,92 @@
+package l2vpn
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// EthernetTypePBB is the EtherType of the Backbone Service Instance Tag (I-TAG).
+const EthernetTypePBB layers.EthernetType = 0x88e7
+
+var LayerTypePBB = gopacket.RegisterLayerType(2004, gopacket.LayerTypeMetadata{Name: "PBB", Decoder: gopacket.DecodeFunc(decodePBB)})
+
+// PBB is the I-TAG of Provider Backbone Bridges (IEEE 802.1ah), which follows the backbone MACs and the optional B-TAG.
+// The customer frame starting with the customer MACs follows the I-TAG.
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	| PCP |D|U| Res |                     I-SID                     |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type PBB struct {
+	Priority           uint8
+	DropEligible       bool
+	UseCustomerAddress bool
+	ISID               uint32
+
+	// DstMAC and SrcMAC are the customer MACs.
+	DstMAC net.HardwareAddr
+	SrcMAC net.HardwareAddr
+
+	layers.BaseLayer
+}
+
+func (b *PBB) LayerType() gopacket.LayerType {
+	return LayerTypePBB
+}
+
+func (b *PBB) CanDecode() gopacket.LayerClass {
+	return LayerTypePBB
+}
+
+func (b *PBB) NextLayerType() gopacket.LayerType {
+	return layers.LayerTypeEthernet
+}
+
+func (b *PBB) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 16 {
+		return &DecodeError{Layer: "PBB", Err: ErrTruncated}
+	}
+
+	b.Priority = data[0] >> 5
+	b.DropEligible = data[0]&0x10 != 0
+	b.UseCustomerAddress = data[0]&0x08 != 0
+	b.ISID = binary.BigEndian.Uint32(data[0:4]) & 0x00FFFFFF
+	b.DstMAC = net.HardwareAddr(data[4:10])
+	b.SrcMAC = net.HardwareAddr(data[10:16])
+	b.BaseLayer = layers.BaseLayer{Contents: data[:4], Payload: data[4:]}
+	return nil
+}
+
+func (b *PBB) SerializeTo(buf gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	bytes, err := buf.PrependBytes(4)
+	if err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint32(bytes, b.ISID&0x00FFFFFF)
+	bytes[0] = (b.Priority & 0x7) << 5
+	if b.DropEligible {
+		bytes[0] |= 0x10
+	}
+	if b.UseCustomerAddress {
+		bytes[0] |= 0x08
+	}
+
+	return nil
+}
+
+func decodePBB(data []byte, p gopacket.PacketBuilder) error {
+	b := &PBB{}
+
+	err := b.DecodeFromBytes(data, p)
+	if err != nil {
+		return err
+	}
+
+	p.AddLayer(b)
+	return p.NextDecoder(layers.LayerTypeEthernet)
+}
@@ -0,0 +1,45 @@
+package l2vpn
+
+import (
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// testPacket6
+// Ethernet II, Src: 00:00:5e:00:53:10 (00:00:5e:00:53:10), Dst: 00:00:5e:00:53:11 (00:00:5e:00:53:11)
+// 802.1ad Virtual LAN, PRI: 0, DEI: 0, ID: 10
+// IEEE 802.1ah, I-SID: 65536, C-Dst: 00:00:5e:00:53:01, C-Src: 00:00:5e:00:53:00
+// Internet Protocol Version 4, Src: 12.0.0.1, Dst: 2.2.2.2
+// Internet Control Message Protocol
+var testPacket6 = append([]byte{
+	0x00, 0x00, 0x5e, 0x00, 0x53, 0x11, 0x00, 0x00, 0x5e, 0x00, 0x53, 0x10, 0x88, 0xa8, 0x00, 0x0a,
+	0x88, 0xe7, 0x00, 0x01, 0x00, 0x00,
+}, testPacket2[22:]...)
+
+func TestPacketPBB(t *testing.T) {
+	Register()
+
+	p := gopacket.NewPacket(testPacket6, layers.LinkTypeEthernet, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet with I-TAG:", p.ErrorLayer().Error())
+	}
+
+	pbb, ok := p.Layer(LayerTypePBB).(*PBB)
+	if !ok {
+		t.Fatal("The packet should have I-TAG")
+	}
+
+	if pbb.ISID != 65536 {
+		t.Errorf("The I-SID should be 65536, but was %d", pbb.ISID)
+	}
+
+	if pbb.SrcMAC.String() != "00:00:5e:00:53:00" || pbb.DstMAC.String() != "00:00:5e:00:53:01" {
+		t.Errorf("The customer MACs should be 00:00:5e:00:53:00 > 00:00:5e:00:53:01, but were %s > %s", pbb.SrcMAC, pbb.DstMAC)
+	}
+
+	if p.Layer(layers.LayerTypeIPv4) == nil {
+		t.Error("The customer frame should be decoded")
+	}
+}
@@ -0,0 +1,137 @@
+package l2vpn
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+var LayerTypePWACH = gopacket.RegisterLayerType(2002, gopacket.LayerTypeMetadata{Name: "PWACH", Decoder: gopacket.DecodeFunc(decodePWACH)})
+
+// PWACHChannelType is the channel type of the PW Associated Channel.
+type PWACHChannelType uint16
+
+const (
+	PWACHChannelTypeMCC      PWACHChannelType = 0x0001
+	PWACHChannelTypeSCC      PWACHChannelType = 0x0002
+	PWACHChannelTypeBFD      PWACHChannelType = 0x0007
+	PWACHChannelTypeDLM      PWACHChannelType = 0x000A
+	PWACHChannelTypeILM      PWACHChannelType = 0x000B
+	PWACHChannelTypeDM       PWACHChannelType = 0x000C
+	PWACHChannelTypeDLMDM    PWACHChannelType = 0x000D
+	PWACHChannelTypeILMDM    PWACHChannelType = 0x000E
+	PWACHChannelTypeIPv4     PWACHChannelType = 0x0021
+	PWACHChannelTypeTPCC     PWACHChannelType = 0x0022
+	PWACHChannelTypeTPCV     PWACHChannelType = 0x0023
+	PWACHChannelTypeIPv6     PWACHChannelType = 0x0057
+	PWACHChannelTypeFaultOAM PWACHChannelType = 0x0058
+)
+
+func (t PWACHChannelType) String() string {
+	switch t {
+	case PWACHChannelTypeMCC:
+		return "MCC"
+	case PWACHChannelTypeSCC:
+		return "SCC"
+	case PWACHChannelTypeBFD:
+		return "VCCV BFD"
+	case PWACHChannelTypeDLM, PWACHChannelTypeILM:
+		return "MPLS Loss Measurement"
+	case PWACHChannelTypeDM:
+		return "MPLS Delay Measurement"
+	case PWACHChannelTypeDLMDM, PWACHChannelTypeILMDM:
+		return "MPLS Loss and Delay Measurement"
+	case PWACHChannelTypeIPv4:
+		return "IPv4 (LSP Ping)"
+	case PWACHChannelTypeTPCC:
+		return "MPLS-TP CC"
+	case PWACHChannelTypeTPCV:
+		return "MPLS-TP CV"
+	case PWACHChannelTypeIPv6:
+		return "IPv6 (LSP Ping)"
+	case PWACHChannelTypeFaultOAM:
+		return "MPLS-TP Fault OAM"
+	default:
+		return fmt.Sprintf("Unknown(0x%04x)", uint16(t))
+	}
+}
+
+// PWACH is the PW Associated Channel Header defined in RFC 4385, which carries VCCV and PW OAM messages.
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|0 0 0 1|Version|   Reserved    |         Channel Type          |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type PWACH struct {
+	Version     uint8
+	ChannelType PWACHChannelType
+	layers.BaseLayer
+}
+
+// IsPWACH tells whether the PW payload starts with the PW Associated Channel Header.
+func IsPWACH(data []byte) bool {
+	return len(data) > 0 && data[0]>>4 == 1
+}
+
+func (ach *PWACH) LayerType() gopacket.LayerType {
+	return LayerTypePWACH
+}
+
+func (ach *PWACH) CanDecode() gopacket.LayerClass {
+	return LayerTypePWACH
+}
+
+func (ach *PWACH) NextLayerType() gopacket.LayerType {
+	switch ach.ChannelType {
+	case PWACHChannelTypeBFD, PWACHChannelTypeTPCC, PWACHChannelTypeTPCV:
+		return layers.LayerTypeBFD
+	case PWACHChannelTypeIPv4:
+		return layers.LayerTypeIPv4
+	case PWACHChannelTypeIPv6:
+		return layers.LayerTypeIPv6
+	default:
+		return gopacket.LayerTypePayload
+	}
+}
+
+func (ach *PWACH) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	bytes, err := b.PrependBytes(4)
+	if err != nil {
+		return err
+	}
+
+	bytes[0] = 0x10 | ach.Version&0x0F
+	bytes[1] = 0
+	binary.BigEndian.PutUint16(bytes[2:], uint16(ach.ChannelType))
+	return nil
+}
+
+func (ach *PWACH) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 4 {
+		return &DecodeError{Layer: "PWACH", Err: ErrTruncated}
+	}
+
+	if !IsPWACH(data) {
+		return &DecodeError{Layer: "PWACH", Err: ErrMissingACH}
+	}
+
+	ach.Version = data[0] & 0x0F
+	ach.ChannelType = PWACHChannelType(binary.BigEndian.Uint16(data[2:4]))
+	ach.BaseLayer = layers.BaseLayer{Contents: data[:4], Payload: data[4:]}
+	return nil
+}
+
+func decodePWACH(data []byte, p gopacket.PacketBuilder) error {
+	ach := &PWACH{}
+
+	err := ach.DecodeFromBytes(data, p)
+	if err != nil {
+		return err
+	}
+
+	p.AddLayer(ach)
+	return p.NextDecoder(ach.NextLayerType())
+}
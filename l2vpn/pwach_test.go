@@ -0,0 +1,45 @@
+package l2vpn
+
+import (
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// testPacket5
+// Ethernet II, Src: cc:15:14:64:00:00 (cc:15:14:64:00:00), Dst: cc:13:14:64:00:01 (cc:13:14:64:00:01)
+// MultiProtocol Label Switching Header, Label: 19, Exp: 0, S: 1, TTL: 1
+// PW Associated Channel Header, Version: 0, Channel Type: BFD Control (0x0007)
+// Bidirectional Forwarding Detection Control Message, State: Up, My Discriminator: 0x00000001, Your Discriminator: 0x00000002
+var testPacket5 = []byte{
+	0xcc, 0x13, 0x14, 0x64, 0x00, 0x01, 0xcc, 0x15, 0x14, 0x64, 0x00, 0x00, 0x88, 0x47, 0x00, 0x01,
+	0x31, 0x01, 0x10, 0x00, 0x00, 0x07, 0x20, 0xc0, 0x03, 0x18, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00,
+	0x00, 0x02, 0x00, 0x0f, 0x42, 0x40, 0x00, 0x0f, 0x42, 0x40, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestPacketPWACH(t *testing.T) {
+	layers.MPLSPayloadDecoder = &PWMCWDecoder{ControlWord: true}
+	p := gopacket.NewPacket(testPacket5, layers.LinkTypeEthernet, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet with ACH:", p.ErrorLayer().Error())
+	}
+
+	ach, ok := p.Layer(LayerTypePWACH).(*PWACH)
+	if !ok {
+		t.Fatal("The packet should have PW Associated Channel Header")
+	}
+
+	if ach.ChannelType != PWACHChannelTypeBFD {
+		t.Errorf("The channel type should be BFD, but was %s", ach.ChannelType)
+	}
+
+	bfd, ok := p.Layer(layers.LayerTypeBFD).(*layers.BFD)
+	if !ok {
+		t.Fatal("The packet should have BFD Control Message")
+	}
+
+	if bfd.State != layers.BFDStateUp || bfd.MyDiscriminator != 1 || bfd.YourDiscriminator != 2 {
+		t.Errorf("The BFD message should be Up with discriminators 1 and 2, but was %+v", bfd)
+	}
+}
@@ -2,7 +2,6 @@ package l2vpn
 
 import (
 	"encoding/binary"
-	"fmt"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
@@ -10,8 +9,18 @@ import (
 
 var LayerTypePWMCW = gopacket.RegisterLayerType(2001, gopacket.LayerTypeMetadata{Name: "PWMCW", Decoder: gopacket.DecodeFunc(decodePWMCW)})
 
+// PWMCW is the Generic PW MPLS Control Word defined in RFC 4385.
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|0 0 0 0| Flags |FRG|  Length   |     Sequence Number           |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
 type PWMCW struct {
-	SequenceNumber uint32
+	Flags          uint8
+	Fragmentation  uint8
+	Length         uint8
+	SequenceNumber uint16
 	layers.BaseLayer
 }
 
@@ -33,17 +42,26 @@ func (cw *PWMCW) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Serialize
 		return err
 	}
 
-	binary.BigEndian.PutUint32(bytes, cw.SequenceNumber)
+	bytes[0] = cw.Flags & 0x0F
+	bytes[1] = (cw.Fragmentation&0x03)<<6 | cw.Length&0x3F
+	binary.BigEndian.PutUint16(bytes[2:], cw.SequenceNumber)
 	return nil
 }
 
 func (cw *PWMCW) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 4 {
+		return &DecodeError{Layer: "PWMCW", Err: ErrTruncated}
+	}
+
 	if data[0]&0xF0 != 0 {
-		return fmt.Errorf("PW MPLS Control Word is missing")
+		return &DecodeError{Layer: "PWMCW", Err: ErrMissingControlWord}
 	}
 
-	cw.SequenceNumber = binary.BigEndian.Uint32(data)
-	cw.BaseLayer = layers.BaseLayer{data[:4], data[4:]}
+	cw.Flags = data[0] & 0x0F
+	cw.Fragmentation = data[1] >> 6
+	cw.Length = data[1] & 0x3F
+	cw.SequenceNumber = binary.BigEndian.Uint16(data[2:4])
+	cw.BaseLayer = layers.BaseLayer{Contents: data[:4], Payload: data[4:]}
 	return nil
 }
 
@@ -65,6 +83,9 @@ type PWMCWDecoder struct {
 
 func (c *PWMCWDecoder) Decode(data []byte, p gopacket.PacketBuilder) error {
 	if c.ControlWord {
+		if IsPWACH(data) {
+			return decodePWACH(data, p)
+		}
 		return decodePWMCW(data, p)
 	}
 
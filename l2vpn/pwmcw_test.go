@@ -1,6 +1,7 @@
 package l2vpn
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/google/gopacket"
@@ -59,3 +60,23 @@ func TestPacketEoMPLSCW(t *testing.T) {
 		t.Error("Failed to decode packet with CW:", p.ErrorLayer().Error())
 	}
 }
+
+func TestPWMCWFields(t *testing.T) {
+	var cw PWMCW
+	if err := cw.DecodeFromBytes([]byte{0x05, 0x8c, 0x12, 0x34}, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal("Failed to decode control word:", err)
+	}
+
+	if cw.Flags != 5 || cw.Fragmentation != 2 || cw.Length != 12 || cw.SequenceNumber != 0x1234 {
+		t.Errorf("The control word should be flags 5, FRG 2, length 12 and sequence 0x1234, but was %+v", cw)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := cw.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal("Failed to serialize control word:", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), []byte{0x05, 0x8c, 0x12, 0x34}) {
+		t.Errorf("The serialized control word should be 058c1234, but was %x", buf.Bytes())
+	}
+}
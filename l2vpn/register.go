@@ -0,0 +1,28 @@
+package l2vpn
+
+import (
+	"sync"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+var registerOnce sync.Once
+
+// Register registers the EtherTypes of this package to gopacket for the packets decoded with gopacket.NewPacket,
+// which is left to the caller not to change the decoding of the other packages importing this package.
+// The EtherTypes are told by the decoders of this package without the registration.
+func Register() {
+	registerOnce.Do(func() {
+		layers.EthernetTypeMetadata[EthernetTypePBB] = layers.EnumMetadata{
+			DecodeWith: gopacket.DecodeFunc(decodePBB),
+			Name:       "PBB",
+			LayerType:  LayerTypePBB,
+		}
+		layers.EthernetTypeMetadata[EthernetTypeERSPANIII] = layers.EnumMetadata{
+			DecodeWith: gopacket.DecodeFunc(decodeERSPANIII),
+			Name:       "ERSPAN Type III",
+			LayerType:  LayerTypeERSPANIII,
+		}
+	})
+}
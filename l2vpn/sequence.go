@@ -0,0 +1,53 @@
+package l2vpn
+
+// SequenceCounter tracks the control word sequence numbers of a pseudowire.
+// The sequence number 0 means that the sender does not use sequencing (RFC 4385),
+// and the number wraps from 65535 to 1.
+type SequenceCounter struct {
+	Received   uint64
+	OutOfOrder uint64
+	Duplicated uint64
+	Lost       uint64
+
+	last    uint16
+	started bool
+}
+
+// distance returns the number of steps from a to b in the 1..65535 sequence space.
+func distance(a, b uint16) uint16 {
+	if b >= a {
+		return b - a
+	}
+	return b - a - 1
+}
+
+// Update accounts the received sequence number.
+func (c *SequenceCounter) Update(seq uint16) {
+	c.Received++
+
+	if seq == 0 {
+		return
+	}
+
+	if !c.started {
+		c.last = seq
+		c.started = true
+		return
+	}
+
+	d := distance(c.last, seq)
+	switch {
+	case d == 0:
+		c.Duplicated++
+	case d < 0x8000:
+		// Frames between the last and the received one are missing
+		c.Lost += uint64(d - 1)
+		c.last = seq
+	default:
+		// A late frame which was counted as lost
+		c.OutOfOrder++
+		if c.Lost > 0 {
+			c.Lost--
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package l2vpn
+
+import (
+	"testing"
+)
+
+func TestSequenceCounter(t *testing.T) {
+	var c SequenceCounter
+	for _, seq := range []uint16{65533, 65534, 1, 1, 4, 3, 5, 0} {
+		c.Update(seq)
+	}
+
+	if c.Received != 8 {
+		t.Errorf("The received frames should be 8, but was %d", c.Received)
+	}
+
+	if c.Duplicated != 1 {
+		t.Errorf("The duplicated frames should be 1, but was %d", c.Duplicated)
+	}
+
+	if c.OutOfOrder != 1 {
+		t.Errorf("The out-of-order frames should be 1, but was %d", c.OutOfOrder)
+	}
+
+	// 65535 and 2 are missing, 3 arrived late
+	if c.Lost != 2 {
+		t.Errorf("The lost frames should be 2, but was %d", c.Lost)
+	}
+}
@@ -0,0 +1,66 @@
+package l2vpn
+
+import (
+	"encoding/binary"
+
+	"github.com/google/gopacket/layers"
+)
+
+// EthernetTypeQinQLegacy is the pre-standard TPID of the S-TAG.
+const EthernetTypeQinQLegacy layers.EthernetType = 0x9100
+
+// VLANTags decodes the 802.1Q/QinQ tags of the customer Ethernet frame.
+// The outermost tag of the stacked tags or the single S-TAG is taken as S-VLAN,
+// and the innermost tag or the single C-TAG is taken as C-VLAN.
+type VLANTags struct {
+	SVLAN uint16
+	CVLAN uint16
+
+	// EthernetType is the EtherType following the tags.
+	EthernetType layers.EthernetType
+}
+
+func isVLANTag(t layers.EthernetType) bool {
+	return t == layers.EthernetTypeDot1Q || t == layers.EthernetTypeQinQ || t == EthernetTypeQinQLegacy
+}
+
+func (v *VLANTags) DecodeFromBytes(data []byte) error {
+	v.SVLAN, v.CVLAN = 0, 0
+
+	if len(data) < 14 {
+		return &DecodeError{Layer: "Ethernet", Err: ErrTruncated}
+	}
+
+	var n int
+	var vid uint16
+	offset := 12
+	outer := layers.EthernetType(binary.BigEndian.Uint16(data[offset:]))
+	v.EthernetType = outer
+
+	for isVLANTag(v.EthernetType) {
+		if len(data) < offset+6 {
+			return &DecodeError{Layer: "Dot1Q", Err: ErrTruncated}
+		}
+
+		vid = binary.BigEndian.Uint16(data[offset+2:]) & 0x0FFF
+		if n == 0 {
+			v.SVLAN = vid
+		}
+
+		n++
+		offset += 4
+		v.EthernetType = layers.EthernetType(binary.BigEndian.Uint16(data[offset:]))
+	}
+
+	switch {
+	case n == 0:
+	case n == 1 && outer != layers.EthernetTypeDot1Q:
+		// A single S-TAG
+	case n == 1:
+		v.SVLAN, v.CVLAN = 0, vid
+	default:
+		v.CVLAN = vid
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package l2vpn
+
+import (
+	"testing"
+
+	"github.com/google/gopacket/layers"
+)
+
+func TestVLANTags(t *testing.T) {
+	untagged := testPacket2[22:]
+	tagged := func(tags ...byte) []byte {
+		data := append([]byte{}, untagged[:12]...)
+		data = append(data, tags...)
+		return append(data, untagged[12:]...)
+	}
+
+	tests := []struct {
+		name         string
+		data         []byte
+		svlan, cvlan uint16
+	}{
+		{"Untagged", untagged, 0, 0},
+		{"C-TAG", tagged(0x81, 0x00, 0x00, 0x64), 0, 100},
+		{"S-TAG", tagged(0x88, 0xa8, 0x00, 0xc8), 200, 0},
+		{"QinQ", tagged(0x88, 0xa8, 0x00, 0xc8, 0x81, 0x00, 0x00, 0x64), 200, 100},
+	}
+
+	for _, tt := range tests {
+		var v VLANTags
+		if err := v.DecodeFromBytes(tt.data); err != nil {
+			t.Errorf("%s: Failed to decode VLAN tags: %v", tt.name, err)
+			continue
+		}
+
+		if v.SVLAN != tt.svlan || v.CVLAN != tt.cvlan {
+			t.Errorf("%s: The VLANs should be S-VLAN %d and C-VLAN %d, but were %d and %d", tt.name, tt.svlan, tt.cvlan, v.SVLAN, v.CVLAN)
+		}
+
+		if v.EthernetType != layers.EthernetTypeIPv4 {
+			t.Errorf("%s: The EtherType should be IPv4, but was %s", tt.name, v.EthernetType)
+		}
+	}
+}
@@ -9,7 +9,81 @@ import (
 
 var LayerTypeVPLS = gopacket.RegisterLayerType(1024, gopacket.LayerTypeMetadata{Name: "VPLS", Decoder: gopacket.DecodeFunc(decodeVPLS)})
 
-type VPLS layers.MPLS
+// LabelELI is the Entropy Label Indicator special purpose label (RFC 6790).
+const LabelELI uint32 = 7
+
+// LabelKind tells the role of a label stack entry.
+type LabelKind uint8
+
+const (
+	LabelKindTransport LabelKind = iota
+	LabelKindPW
+	LabelKindFlow
+	LabelKindELI
+	LabelKindEntropy
+	LabelKindESI
+)
+
+func (k LabelKind) String() string {
+	switch k {
+	case LabelKindTransport:
+		return "Transport"
+	case LabelKindPW:
+		return "PW"
+	case LabelKindFlow:
+		return "Flow"
+	case LabelKindELI:
+		return "ELI"
+	case LabelKindEntropy:
+		return "Entropy"
+	case LabelKindESI:
+		return "ESI"
+	default:
+		return "Unknown"
+	}
+}
+
+// LabelStackEntry is a single 4-byte entry of the MPLS label stack.
+type LabelStackEntry struct {
+	Label        uint32
+	TrafficClass uint8
+	StackBottom  bool
+	TTL          uint8
+	Kind         LabelKind
+}
+
+// VPLS walks the whole MPLS label stack until the bottom of stack.
+// The embedded LabelStackEntry holds the PW label found at PWLabelIndex.
+type VPLS struct {
+	LabelStackEntry
+
+	// Stack holds every label stack entry from the top to the bottom.
+	Stack []LabelStackEntry
+
+	// PWLabelIndex is the position of the PW label counted from the bottom of the stack,
+	// skipping the FAT flow label and the entropy labels. 0 means the bottom-most candidate.
+	PWLabelIndex int
+
+	// FAT tells that the bottom-of-stack label is a flow label of FAT-PW (RFC 6391).
+	FAT bool
+
+	// DetectFAT takes the bottom-of-stack label with the TTL 0 as a flow label,
+	// as RFC 6391 sets the TTL of the flow label to 0.
+	DetectFAT bool
+
+	// FlowLabel points to the FAT flow label in Stack, or nil if FAT is disabled.
+	FlowLabel *LabelStackEntry
+
+	// EntropyLabels holds the entropy labels found after an ELI (RFC 6790).
+	EntropyLabels []LabelStackEntry
+
+	// ESILabel points to the EVPN ESI label in Stack, or nil if SplitESILabel has not been applied.
+	ESILabel *LabelStackEntry
+
+	pwIndex int
+
+	layers.BaseLayer
+}
 
 func (v *VPLS) LayerType() gopacket.LayerType {
 	return layers.LayerTypeMPLS
@@ -20,13 +94,135 @@ func (v *VPLS) NextLayerType() gopacket.LayerType {
 }
 
 func (v *VPLS) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
-	decoded := binary.BigEndian.Uint32(data[:4])
+	v.Stack = v.Stack[:0]
 
-	v.Label = decoded >> 12
-	v.TrafficClass = uint8(decoded>>9) & 0x7
-	v.StackBottom = decoded&0x100 != 0
-	v.TTL = uint8(decoded)
-	v.BaseLayer = layers.BaseLayer{data[:4], data[4:]}
+	var offset int
+	for {
+		if len(data) < offset+4 {
+			return &DecodeError{Layer: "VPLS", Err: ErrTruncated}
+		}
+
+		decoded := binary.BigEndian.Uint32(data[offset : offset+4])
+		offset += 4
+
+		e := LabelStackEntry{
+			Label:        decoded >> 12,
+			TrafficClass: uint8(decoded>>9) & 0x7,
+			StackBottom:  decoded&0x100 != 0,
+			TTL:          uint8(decoded),
+		}
+		v.Stack = append(v.Stack, e)
+
+		if e.StackBottom {
+			break
+		}
+	}
+	v.BaseLayer = layers.BaseLayer{Contents: data[:offset], Payload: data[offset:]}
+
+	// The entropy label also has the TTL 0
+	n := len(v.Stack)
+	fat := v.FAT || v.DetectFAT && n > 1 && v.Stack[n-1].TTL == 0 && v.Stack[n-2].Label != LabelELI
+
+	return v.PickPW(fat)
+}
+
+// PickPW picks the PW label from Stack again, taking the bottom-of-stack label as a flow label if fat is set.
+// It is used when the PW is told to be FAT-PW or not after decoding, such as by the label store.
+func (v *VPLS) PickPW(fat bool) error {
+	for i := range v.Stack {
+		v.Stack[i].Kind = LabelKindTransport
+	}
+
+	v.FlowLabel = nil
+	v.ESILabel = nil
+	v.EntropyLabels = v.EntropyLabels[:0]
+
+	// Mark ELI and the entropy label following it
+	for i := 0; i < len(v.Stack); i++ {
+		if v.Stack[i].Label == LabelELI && i+1 < len(v.Stack) {
+			v.Stack[i].Kind = LabelKindELI
+			v.Stack[i+1].Kind = LabelKindEntropy
+			v.EntropyLabels = append(v.EntropyLabels, v.Stack[i+1])
+			i++
+		}
+	}
+
+	bottom := len(v.Stack) - 1
+	if fat {
+		if bottom < 1 || v.Stack[bottom].Kind != LabelKindTransport {
+			return &DecodeError{Layer: "VPLS", Err: ErrNoFlowLabel}
+		}
+
+		v.Stack[bottom].Kind = LabelKindFlow
+		v.FlowLabel = &v.Stack[bottom]
+		bottom--
+	}
+
+	// Find the PW label skipping ELI and entropy labels
+	n := v.PWLabelIndex
+	i := bottom
+	for ; i >= 0; i-- {
+		if v.Stack[i].Kind != LabelKindTransport {
+			continue
+		}
+		if n == 0 {
+			break
+		}
+		n--
+	}
+
+	if i < 0 {
+		return &DecodeError{Layer: "VPLS", Err: ErrNoPWLabel}
+	}
+
+	v.Stack[i].Kind = LabelKindPW
+	v.LabelStackEntry = v.Stack[i]
+	v.pwIndex = i
+
+	return nil
+}
+
+// SplitESILabel takes the PW label as the ESI label of EVPN (RFC 7432) and the label above it as the EVI label.
+// The ESI label can not be told from its value, so the caller needs to know it from the label store.
+// It returns false if there is no label above the ESI label.
+func (v *VPLS) SplitESILabel() bool {
+	for i := v.pwIndex - 1; i >= 0; i-- {
+		if v.Stack[i].Kind != LabelKindTransport {
+			continue
+		}
+
+		v.Stack[v.pwIndex].Kind = LabelKindESI
+		v.ESILabel = &v.Stack[v.pwIndex]
+
+		v.Stack[i].Kind = LabelKindPW
+		v.LabelStackEntry = v.Stack[i]
+		v.pwIndex = i
+		return true
+	}
+
+	return false
+}
+
+// SerializeTo writes the label stack from Stack, or the PW label alone if Stack is empty.
+// The bottom-of-stack bit is set on the last entry regardless of StackBottom.
+func (v *VPLS) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	stack := v.Stack
+	if len(stack) == 0 {
+		stack = []LabelStackEntry{v.LabelStackEntry}
+	}
+
+	bytes, err := b.PrependBytes(4 * len(stack))
+	if err != nil {
+		return err
+	}
+
+	for i, e := range stack {
+		encoded := e.Label<<12 | uint32(e.TrafficClass&0x7)<<9 | uint32(e.TTL)
+		if i == len(stack)-1 {
+			encoded |= 0x100
+		}
+		binary.BigEndian.PutUint32(bytes[4*i:], encoded)
+	}
 
 	return nil
 }
@@ -39,12 +235,7 @@ func decodeVPLS(data []byte, p gopacket.PacketBuilder) error {
 	}
 
 	p.AddLayer(vpls)
-
-	if vpls.StackBottom {
-		return p.NextDecoder(layers.MPLSPayloadDecoder)
-	}
-
-	return p.NextDecoder(gopacket.DecodeFunc(decodeVPLS))
+	return p.NextDecoder(layers.MPLSPayloadDecoder)
 }
 
 func (v *VPLS) CanDecode() gopacket.LayerClass {
@@ -0,0 +1,191 @@
+package l2vpn
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// testPacket3
+// Ethernet II, Src: cc:15:14:64:00:00 (cc:15:14:64:00:00), Dst: cc:13:14:64:00:01 (cc:13:14:64:00:01)
+// MultiProtocol Label Switching Header, Label: 16001, Exp: 0, S: 0, TTL: 254
+// MultiProtocol Label Switching Header, Label: 19, Exp: 5, S: 1, TTL: 254
+// GeneralPWMCW, 00:00:00:00
+// Ethernet II, Src: 00:00:5e:00:53:00 (00:00:5e:00:53:00), Dst: 00:00:5e:00:53:01 (00:00:5e:00:53:01)
+// Internet Protocol Version 4, Src: 12.0.0.1, Dst: 2.2.2.2
+// Internet Control Message Protocol
+var testPacket3 = append([]byte{
+	0xcc, 0x13, 0x14, 0x64, 0x00, 0x01, 0xcc, 0x15, 0x14, 0x64, 0x00, 0x00, 0x88, 0x47, 0x03, 0xe8,
+	0x10, 0xfe, 0x00, 0x01, 0x3b, 0xfe,
+}, testPacket2[18:]...)
+
+// testPacket4
+// Ethernet II, Src: cc:15:14:64:00:00 (cc:15:14:64:00:00), Dst: cc:13:14:64:00:01 (cc:13:14:64:00:01)
+// MultiProtocol Label Switching Header, Label: 16001, Exp: 0, S: 0, TTL: 254
+// MultiProtocol Label Switching Header, Label: 7 (Entropy Label Indicator), Exp: 0, S: 0, TTL: 0
+// MultiProtocol Label Switching Header, Label: 524287, Exp: 0, S: 0, TTL: 0
+// MultiProtocol Label Switching Header, Label: 19, Exp: 0, S: 0, TTL: 254
+// MultiProtocol Label Switching Header, Label: 300000, Exp: 0, S: 1, TTL: 0
+// GeneralPWMCW, 00:00:00:00
+// Ethernet II, Src: 00:00:5e:00:53:00 (00:00:5e:00:53:00), Dst: 00:00:5e:00:53:01 (00:00:5e:00:53:01)
+// Internet Protocol Version 4, Src: 12.0.0.1, Dst: 2.2.2.2
+// Internet Control Message Protocol
+var testPacket4 = append([]byte{
+	0xcc, 0x13, 0x14, 0x64, 0x00, 0x01, 0xcc, 0x15, 0x14, 0x64, 0x00, 0x00, 0x88, 0x47, 0x03, 0xe8,
+	0x10, 0xfe, 0x00, 0x00, 0x70, 0x00, 0x7f, 0xff, 0xf0, 0x00, 0x00, 0x01, 0x30, 0xfe, 0x49, 0x3e,
+	0x01, 0x00,
+}, testPacket2[18:]...)
+
+func decodeVPLSLayers(t *testing.T, data []byte, vpls *VPLS) []gopacket.LayerType {
+	var eth layers.Ethernet
+	var pwmcw PWMCW
+
+	decoded := make([]gopacket.LayerType, 0, 3)
+
+	parser := gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &eth)
+	parser.DecodeLayers(data, &decoded)
+
+	parser = gopacket.NewDecodingLayerParser(layers.LayerTypeMPLS, vpls, &pwmcw, &eth)
+	err := parser.DecodeLayers(eth.Payload, &decoded)
+	if _, ok := err.(gopacket.UnsupportedLayerType); err != nil && !ok {
+		t.Fatal("Failed to decode packet:", err)
+	}
+
+	return decoded
+}
+
+func TestVPLSLabelStack(t *testing.T) {
+	var vpls VPLS
+	decoded := decodeVPLSLayers(t, testPacket3, &vpls)
+
+	if len(decoded) != 3 || decoded[1] != LayerTypePWMCW || decoded[2] != layers.LayerTypeEthernet {
+		t.Fatalf("The decoded layers should be MPLS, PWMCW and Ethernet, but were %v", decoded)
+	}
+
+	if len(vpls.Stack) != 2 {
+		t.Fatalf("The label stack should have 2 entries, but had %d", len(vpls.Stack))
+	}
+
+	if vpls.Stack[0].Label != 16001 || vpls.Stack[0].StackBottom {
+		t.Errorf("The top label should be 16001 without S-bit, but was %+v", vpls.Stack[0])
+	}
+
+	if vpls.Label != 19 || vpls.TrafficClass != 5 || !vpls.StackBottom || vpls.TTL != 254 {
+		t.Errorf("The PW label should be 19 with TC 5 and TTL 254, but was %+v", vpls.LabelStackEntry)
+	}
+}
+
+func TestVPLSPWLabelIndex(t *testing.T) {
+	vpls := VPLS{PWLabelIndex: 1}
+	decodeVPLSLayers(t, testPacket3, &vpls)
+
+	if vpls.Label != 16001 {
+		t.Errorf("The PW label at index 1 should be 16001, but was %d", vpls.Label)
+	}
+
+	vpls = VPLS{PWLabelIndex: 2}
+	if err := vpls.DecodeFromBytes(testPacket3[14:], gopacket.NilDecodeFeedback); err == nil {
+		t.Error("Decoding a label stack shorter than the PW label index should fail")
+	}
+}
+
+func TestVPLSTruncated(t *testing.T) {
+	var vpls VPLS
+	if err := vpls.DecodeFromBytes(testPacket3[14:20], gopacket.NilDecodeFeedback); err == nil {
+		t.Error("Decoding a truncated label stack should fail")
+	}
+}
+
+func TestVPLSFlowAndEntropyLabels(t *testing.T) {
+	vpls := VPLS{FAT: true}
+	decodeVPLSLayers(t, testPacket4, &vpls)
+
+	if vpls.Label != 19 {
+		t.Errorf("The PW label should be 19, but was %d", vpls.Label)
+	}
+
+	if vpls.FlowLabel == nil || vpls.FlowLabel.Label != 300000 {
+		t.Errorf("The flow label should be 300000, but was %+v", vpls.FlowLabel)
+	}
+
+	if len(vpls.EntropyLabels) != 1 || vpls.EntropyLabels[0].Label != 524287 {
+		t.Errorf("The entropy label should be 524287, but was %+v", vpls.EntropyLabels)
+	}
+
+	kinds := []LabelKind{LabelKindTransport, LabelKindELI, LabelKindEntropy, LabelKindPW, LabelKindFlow}
+	for i, e := range vpls.Stack {
+		if e.Kind != kinds[i] {
+			t.Errorf("The label %d should be %s, but was %s", e.Label, kinds[i], e.Kind)
+		}
+	}
+
+	vpls = VPLS{}
+	decodeVPLSLayers(t, testPacket4, &vpls)
+
+	if vpls.Label != 300000 || vpls.FlowLabel != nil {
+		t.Errorf("The PW label without FAT should be the bottom label 300000, but was %d", vpls.Label)
+	}
+
+	// The flow label is told by its TTL 0
+	vpls = VPLS{DetectFAT: true}
+	decodeVPLSLayers(t, testPacket4, &vpls)
+
+	if vpls.Label != 19 || vpls.FlowLabel == nil {
+		t.Errorf("The bottom label with the TTL 0 should be the flow label, but the PW label was %d", vpls.Label)
+	}
+
+	if err := vpls.PickPW(false); err != nil || vpls.Label != 300000 || vpls.FlowLabel != nil {
+		t.Errorf("The PW label picked again without FAT should be the bottom label 300000, but was %d (%v)", vpls.Label, err)
+	}
+	if err := vpls.PickPW(true); err != nil || vpls.Label != 19 || vpls.Stack[4].Kind != LabelKindFlow {
+		t.Errorf("The PW label picked again with FAT should be 19, but was %d (%v)", vpls.Label, err)
+	}
+}
+
+func TestVPLSSplitESILabel(t *testing.T) {
+	var vpls VPLS
+	decodeVPLSLayers(t, testPacket3, &vpls)
+
+	if !vpls.SplitESILabel() {
+		t.Fatal("The label stack should have an EVI label above the ESI label")
+	}
+
+	if vpls.Label != 16001 || vpls.Stack[0].Kind != LabelKindPW {
+		t.Errorf("The EVI label should be 16001, but was %d", vpls.Label)
+	}
+
+	if vpls.ESILabel == nil || vpls.ESILabel.Label != 19 || vpls.Stack[1].Kind != LabelKindESI {
+		t.Errorf("The ESI label should be 19, but was %+v", vpls.ESILabel)
+	}
+
+	if vpls.SplitESILabel() {
+		t.Error("The label stack should have no label above the EVI label")
+	}
+}
+
+func TestVPLSSerialize(t *testing.T) {
+	var vpls VPLS
+	decodeVPLSLayers(t, testPacket4, &vpls)
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := vpls.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal("Failed to serialize label stack:", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), testPacket4[14:34]) {
+		t.Errorf("The serialized label stack should be %x, but was %x", testPacket4[14:34], buf.Bytes())
+	}
+
+	pw := VPLS{LabelStackEntry: LabelStackEntry{Label: 19, TrafficClass: 5, TTL: 254}}
+
+	buf = gopacket.NewSerializeBuffer()
+	if err := pw.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal("Failed to serialize PW label:", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), testPacket3[18:22]) {
+		t.Errorf("The serialized PW label should be %x, but was %x", testPacket3[18:22], buf.Bytes())
+	}
+}
@@ -21,33 +21,1069 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PacketKind int32
+
+const (
+	PacketKind_DATA PacketKind = 0
+	PacketKind_OAM  PacketKind = 1
+)
+
+// Enum value maps for PacketKind.
+var (
+	PacketKind_name = map[int32]string{
+		0: "DATA",
+		1: "OAM",
+	}
+	PacketKind_value = map[string]int32{
+		"DATA": 0,
+		"OAM":  1,
+	}
+)
+
+func (x PacketKind) Enum() *PacketKind {
+	p := new(PacketKind)
+	*p = x
+	return p
+}
+
+func (x PacketKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PacketKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_bumstream_proto_enumTypes[0].Descriptor()
+}
+
+func (PacketKind) Type() protoreflect.EnumType {
+	return &file_bumstream_proto_enumTypes[0]
+}
+
+func (x PacketKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PacketKind.Descriptor instead.
+func (PacketKind) EnumDescriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{0}
+}
+
+type LabelKind int32
+
+const (
+	LabelKind_TRANSPORT LabelKind = 0
+	LabelKind_PW        LabelKind = 1
+	LabelKind_FLOW      LabelKind = 2
+	LabelKind_ELI       LabelKind = 3
+	LabelKind_ENTROPY   LabelKind = 4
+	LabelKind_ESI       LabelKind = 5
+)
+
+// Enum value maps for LabelKind.
+var (
+	LabelKind_name = map[int32]string{
+		0: "TRANSPORT",
+		1: "PW",
+		2: "FLOW",
+		3: "ELI",
+		4: "ENTROPY",
+		5: "ESI",
+	}
+	LabelKind_value = map[string]int32{
+		"TRANSPORT": 0,
+		"PW":        1,
+		"FLOW":      2,
+		"ELI":       3,
+		"ENTROPY":   4,
+		"ESI":       5,
+	}
+)
+
+func (x LabelKind) Enum() *LabelKind {
+	p := new(LabelKind)
+	*p = x
+	return p
+}
+
+func (x LabelKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LabelKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_bumstream_proto_enumTypes[1].Descriptor()
+}
+
+func (LabelKind) Type() protoreflect.EnumType {
+	return &file_bumstream_proto_enumTypes[1]
+}
+
+func (x LabelKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LabelKind.Descriptor instead.
+func (LabelKind) EnumDescriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{1}
+}
+
+type ServiceType int32
+
+const (
+	ServiceType_VPLS ServiceType = 0
+	ServiceType_EVPN ServiceType = 1
+)
+
+// Enum value maps for ServiceType.
+var (
+	ServiceType_name = map[int32]string{
+		0: "VPLS",
+		1: "EVPN",
+	}
+	ServiceType_value = map[string]int32{
+		"VPLS": 0,
+		"EVPN": 1,
+	}
+)
+
+func (x ServiceType) Enum() *ServiceType {
+	p := new(ServiceType)
+	*p = x
+	return p
+}
+
+func (x ServiceType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ServiceType) Descriptor() protoreflect.EnumDescriptor {
+	return file_bumstream_proto_enumTypes[2].Descriptor()
+}
+
+func (ServiceType) Type() protoreflect.EnumType {
+	return &file_bumstream_proto_enumTypes[2]
+}
+
+func (x ServiceType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ServiceType.Descriptor instead.
+func (ServiceType) EnumDescriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{2}
+}
+
+type EncapType int32
+
+const (
+	EncapType_ETHERNET EncapType = 0
+	EncapType_GRE      EncapType = 1
+	EncapType_UDP      EncapType = 2
+	EncapType_ERSPAN   EncapType = 3
+	EncapType_VXLAN    EncapType = 4
+)
+
+// Enum value maps for EncapType.
+var (
+	EncapType_name = map[int32]string{
+		0: "ETHERNET",
+		1: "GRE",
+		2: "UDP",
+		3: "ERSPAN",
+		4: "VXLAN",
+	}
+	EncapType_value = map[string]int32{
+		"ETHERNET": 0,
+		"GRE":      1,
+		"UDP":      2,
+		"ERSPAN":   3,
+		"VXLAN":    4,
+	}
+)
+
+func (x EncapType) Enum() *EncapType {
+	p := new(EncapType)
+	*p = x
+	return p
+}
+
+func (x EncapType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EncapType) Descriptor() protoreflect.EnumDescriptor {
+	return file_bumstream_proto_enumTypes[3].Descriptor()
+}
+
+func (EncapType) Type() protoreflect.EnumType {
+	return &file_bumstream_proto_enumTypes[3]
+}
+
+func (x EncapType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EncapType.Descriptor instead.
+func (EncapType) EnumDescriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{3}
+}
+
 type Request struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Filter string `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
-	Remote string `protobuf:"bytes,2,opt,name=remote,proto3" json:"remote,omitempty"`
-	Domain string `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	Filter    string     `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Remote    string     `protobuf:"bytes,2,opt,name=remote,proto3" json:"remote,omitempty"`
+	Domain    string     `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	Kind      PacketKind `protobuf:"varint,4,opt,name=kind,proto3,enum=protobuf.PacketKind" json:"kind,omitempty"`
+	Svlan     uint32     `protobuf:"varint,5,opt,name=svlan,proto3" json:"svlan,omitempty"`
+	Cvlan     uint32     `protobuf:"varint,6,opt,name=cvlan,proto3" json:"cvlan,omitempty"`
+	Interface string     `protobuf:"bytes,7,opt,name=interface,proto3" json:"interface,omitempty"`
+}
+
+func (x *Request) Reset() {
+	*x = Request{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Request) ProtoMessage() {}
+
+func (x *Request) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Request.ProtoReflect.Descriptor instead.
+func (*Request) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Request) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *Request) GetRemote() string {
+	if x != nil {
+		return x.Remote
+	}
+	return ""
+}
+
+func (x *Request) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Request) GetKind() PacketKind {
+	if x != nil {
+		return x.Kind
+	}
+	return PacketKind_DATA
+}
+
+func (x *Request) GetSvlan() uint32 {
+	if x != nil {
+		return x.Svlan
+	}
+	return 0
+}
+
+func (x *Request) GetCvlan() uint32 {
+	if x != nil {
+		return x.Cvlan
+	}
+	return 0
+}
+
+func (x *Request) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+type Outer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Encap         EncapType `protobuf:"varint,1,opt,name=encap,proto3,enum=protobuf.EncapType" json:"encap,omitempty"`
+	Vlans         []uint32  `protobuf:"varint,2,rep,packed,name=vlans,proto3" json:"vlans,omitempty"`
+	Srcmac        string    `protobuf:"bytes,3,opt,name=srcmac,proto3" json:"srcmac,omitempty"`
+	Dstmac        string    `protobuf:"bytes,4,opt,name=dstmac,proto3" json:"dstmac,omitempty"`
+	Srcip         string    `protobuf:"bytes,5,opt,name=srcip,proto3" json:"srcip,omitempty"`
+	Dstip         string    `protobuf:"bytes,6,opt,name=dstip,proto3" json:"dstip,omitempty"`
+	Erspanversion uint32    `protobuf:"varint,7,opt,name=erspanversion,proto3" json:"erspanversion,omitempty"`
+	Sessionid     uint32    `protobuf:"varint,8,opt,name=sessionid,proto3" json:"sessionid,omitempty"`
+	Timestamp     uint32    `protobuf:"varint,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Granularity   uint32    `protobuf:"varint,10,opt,name=granularity,proto3" json:"granularity,omitempty"`
+}
+
+func (x *Outer) Reset() {
+	*x = Outer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Outer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Outer) ProtoMessage() {}
+
+func (x *Outer) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Outer.ProtoReflect.Descriptor instead.
+func (*Outer) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Outer) GetEncap() EncapType {
+	if x != nil {
+		return x.Encap
+	}
+	return EncapType_ETHERNET
+}
+
+func (x *Outer) GetVlans() []uint32 {
+	if x != nil {
+		return x.Vlans
+	}
+	return nil
+}
+
+func (x *Outer) GetSrcmac() string {
+	if x != nil {
+		return x.Srcmac
+	}
+	return ""
+}
+
+func (x *Outer) GetDstmac() string {
+	if x != nil {
+		return x.Dstmac
+	}
+	return ""
+}
+
+func (x *Outer) GetSrcip() string {
+	if x != nil {
+		return x.Srcip
+	}
+	return ""
+}
+
+func (x *Outer) GetDstip() string {
+	if x != nil {
+		return x.Dstip
+	}
+	return ""
+}
+
+func (x *Outer) GetErspanversion() uint32 {
+	if x != nil {
+		return x.Erspanversion
+	}
+	return 0
+}
+
+func (x *Outer) GetSessionid() uint32 {
+	if x != nil {
+		return x.Sessionid
+	}
+	return 0
+}
+
+func (x *Outer) GetTimestamp() uint32 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Outer) GetGranularity() uint32 {
+	if x != nil {
+		return x.Granularity
+	}
+	return 0
+}
+
+type Backbone struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Srcmac string `protobuf:"bytes,1,opt,name=srcmac,proto3" json:"srcmac,omitempty"`
+	Dstmac string `protobuf:"bytes,2,opt,name=dstmac,proto3" json:"dstmac,omitempty"`
+	Bvid   uint32 `protobuf:"varint,3,opt,name=bvid,proto3" json:"bvid,omitempty"`
+	Isid   uint32 `protobuf:"varint,4,opt,name=isid,proto3" json:"isid,omitempty"`
+}
+
+func (x *Backbone) Reset() {
+	*x = Backbone{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Backbone) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Backbone) ProtoMessage() {}
+
+func (x *Backbone) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Backbone.ProtoReflect.Descriptor instead.
+func (*Backbone) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Backbone) GetSrcmac() string {
+	if x != nil {
+		return x.Srcmac
+	}
+	return ""
+}
+
+func (x *Backbone) GetDstmac() string {
+	if x != nil {
+		return x.Dstmac
+	}
+	return ""
+}
+
+func (x *Backbone) GetBvid() uint32 {
+	if x != nil {
+		return x.Bvid
+	}
+	return 0
+}
+
+func (x *Backbone) GetIsid() uint32 {
+	if x != nil {
+		return x.Isid
+	}
+	return 0
+}
+
+type LabelStackEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Label  uint32    `protobuf:"varint,1,opt,name=label,proto3" json:"label,omitempty"`
+	Tc     uint32    `protobuf:"varint,2,opt,name=tc,proto3" json:"tc,omitempty"`
+	Bottom bool      `protobuf:"varint,3,opt,name=bottom,proto3" json:"bottom,omitempty"`
+	Ttl    uint32    `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Kind   LabelKind `protobuf:"varint,5,opt,name=kind,proto3,enum=protobuf.LabelKind" json:"kind,omitempty"`
+}
+
+func (x *LabelStackEntry) Reset() {
+	*x = LabelStackEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LabelStackEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LabelStackEntry) ProtoMessage() {}
+
+func (x *LabelStackEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelStackEntry.ProtoReflect.Descriptor instead.
+func (*LabelStackEntry) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LabelStackEntry) GetLabel() uint32 {
+	if x != nil {
+		return x.Label
+	}
+	return 0
+}
+
+func (x *LabelStackEntry) GetTc() uint32 {
+	if x != nil {
+		return x.Tc
+	}
+	return 0
+}
+
+func (x *LabelStackEntry) GetBottom() bool {
+	if x != nil {
+		return x.Bottom
+	}
+	return false
+}
+
+func (x *LabelStackEntry) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *LabelStackEntry) GetKind() LabelKind {
+	if x != nil {
+		return x.Kind
+	}
+	return LabelKind_TRANSPORT
+}
+
+type Packet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data        []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Label       uint32                 `protobuf:"varint,2,opt,name=label,proto3" json:"label,omitempty"`
+	Remote      string                 `protobuf:"bytes,3,opt,name=remote,proto3" json:"remote,omitempty"`
+	Domain      string                 `protobuf:"bytes,4,opt,name=domain,proto3" json:"domain,omitempty"`
+	Peerid      string                 `protobuf:"bytes,5,opt,name=peerid,proto3" json:"peerid,omitempty"`
+	Timestamp   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Labels      []*LabelStackEntry     `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty"`
+	Sequence    uint32                 `protobuf:"varint,8,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Kind        PacketKind             `protobuf:"varint,9,opt,name=kind,proto3,enum=protobuf.PacketKind" json:"kind,omitempty"`
+	Channel     uint32                 `protobuf:"varint,10,opt,name=channel,proto3" json:"channel,omitempty"`
+	Outer       *Outer                 `protobuf:"bytes,11,opt,name=outer,proto3" json:"outer,omitempty"`
+	Service     ServiceType            `protobuf:"varint,12,opt,name=service,proto3,enum=protobuf.ServiceType" json:"service,omitempty"`
+	Esi         string                 `protobuf:"bytes,13,opt,name=esi,proto3" json:"esi,omitempty"`
+	Vni         uint32                 `protobuf:"varint,14,opt,name=vni,proto3" json:"vni,omitempty"`
+	Backbone    *Backbone              `protobuf:"bytes,15,opt,name=backbone,proto3" json:"backbone,omitempty"`
+	Svlan       uint32                 `protobuf:"varint,16,opt,name=svlan,proto3" json:"svlan,omitempty"`
+	Cvlan       uint32                 `protobuf:"varint,17,opt,name=cvlan,proto3" json:"cvlan,omitempty"`
+	Ethertype   uint32                 `protobuf:"varint,18,opt,name=ethertype,proto3" json:"ethertype,omitempty"`
+	Controlword bool                   `protobuf:"varint,19,opt,name=controlword,proto3" json:"controlword,omitempty"`
+	Interface   string                 `protobuf:"bytes,20,opt,name=interface,proto3" json:"interface,omitempty"`
+}
+
+func (x *Packet) Reset() {
+	*x = Packet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Packet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Packet) ProtoMessage() {}
+
+func (x *Packet) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Packet.ProtoReflect.Descriptor instead.
+func (*Packet) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Packet) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Packet) GetLabel() uint32 {
+	if x != nil {
+		return x.Label
+	}
+	return 0
+}
+
+func (x *Packet) GetRemote() string {
+	if x != nil {
+		return x.Remote
+	}
+	return ""
+}
+
+func (x *Packet) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Packet) GetPeerid() string {
+	if x != nil {
+		return x.Peerid
+	}
+	return ""
+}
+
+func (x *Packet) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Packet) GetLabels() []*LabelStackEntry {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Packet) GetSequence() uint32 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *Packet) GetKind() PacketKind {
+	if x != nil {
+		return x.Kind
+	}
+	return PacketKind_DATA
+}
+
+func (x *Packet) GetChannel() uint32 {
+	if x != nil {
+		return x.Channel
+	}
+	return 0
+}
+
+func (x *Packet) GetOuter() *Outer {
+	if x != nil {
+		return x.Outer
+	}
+	return nil
+}
+
+func (x *Packet) GetService() ServiceType {
+	if x != nil {
+		return x.Service
+	}
+	return ServiceType_VPLS
+}
+
+func (x *Packet) GetEsi() string {
+	if x != nil {
+		return x.Esi
+	}
+	return ""
+}
+
+func (x *Packet) GetVni() uint32 {
+	if x != nil {
+		return x.Vni
+	}
+	return 0
+}
+
+func (x *Packet) GetBackbone() *Backbone {
+	if x != nil {
+		return x.Backbone
+	}
+	return nil
+}
+
+func (x *Packet) GetSvlan() uint32 {
+	if x != nil {
+		return x.Svlan
+	}
+	return 0
+}
+
+func (x *Packet) GetCvlan() uint32 {
+	if x != nil {
+		return x.Cvlan
+	}
+	return 0
+}
+
+func (x *Packet) GetEthertype() uint32 {
+	if x != nil {
+		return x.Ethertype
+	}
+	return 0
+}
+
+func (x *Packet) GetControlword() bool {
+	if x != nil {
+		return x.Controlword
+	}
+	return false
+}
+
+func (x *Packet) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+type StatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{5}
+}
+
+type PWStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Label      uint32 `protobuf:"varint,1,opt,name=label,proto3" json:"label,omitempty"`
+	Remote     string `protobuf:"bytes,2,opt,name=remote,proto3" json:"remote,omitempty"`
+	Domain     string `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	Received   uint64 `protobuf:"varint,4,opt,name=received,proto3" json:"received,omitempty"`
+	Lost       uint64 `protobuf:"varint,5,opt,name=lost,proto3" json:"lost,omitempty"`
+	Outoforder uint64 `protobuf:"varint,6,opt,name=outoforder,proto3" json:"outoforder,omitempty"`
+	Duplicated uint64 `protobuf:"varint,7,opt,name=duplicated,proto3" json:"duplicated,omitempty"`
+	Context    string `protobuf:"bytes,8,opt,name=context,proto3" json:"context,omitempty"`
+}
+
+func (x *PWStats) Reset() {
+	*x = PWStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PWStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PWStats) ProtoMessage() {}
+
+func (x *PWStats) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PWStats.ProtoReflect.Descriptor instead.
+func (*PWStats) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PWStats) GetLabel() uint32 {
+	if x != nil {
+		return x.Label
+	}
+	return 0
+}
+
+func (x *PWStats) GetRemote() string {
+	if x != nil {
+		return x.Remote
+	}
+	return ""
+}
+
+func (x *PWStats) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *PWStats) GetReceived() uint64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *PWStats) GetLost() uint64 {
+	if x != nil {
+		return x.Lost
+	}
+	return 0
+}
+
+func (x *PWStats) GetOutoforder() uint64 {
+	if x != nil {
+		return x.Outoforder
+	}
+	return 0
+}
+
+func (x *PWStats) GetDuplicated() uint64 {
+	if x != nil {
+		return x.Duplicated
+	}
+	return 0
+}
+
+func (x *PWStats) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+type CaptureStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Interface string `protobuf:"bytes,1,opt,name=interface,proto3" json:"interface,omitempty"`
+	Received  uint64 `protobuf:"varint,2,opt,name=received,proto3" json:"received,omitempty"`
+	Dropped   uint64 `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	Ifdropped uint64 `protobuf:"varint,4,opt,name=ifdropped,proto3" json:"ifdropped,omitempty"`
+}
+
+func (x *CaptureStats) Reset() {
+	*x = CaptureStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CaptureStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureStats) ProtoMessage() {}
+
+func (x *CaptureStats) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureStats.ProtoReflect.Descriptor instead.
+func (*CaptureStats) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CaptureStats) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *CaptureStats) GetReceived() uint64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (x *CaptureStats) GetIfdropped() uint64 {
+	if x != nil {
+		return x.Ifdropped
+	}
+	return 0
+}
+
+type SubscriberStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id      string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Dropped uint64 `protobuf:"varint,2,opt,name=dropped,proto3" json:"dropped,omitempty"`
+}
+
+func (x *SubscriberStats) Reset() {
+	*x = SubscriberStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscriberStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriberStats) ProtoMessage() {}
+
+func (x *SubscriberStats) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriberStats.ProtoReflect.Descriptor instead.
+func (*SubscriberStats) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SubscriberStats) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SubscriberStats) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type StatsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pwstats     []*PWStats         `protobuf:"bytes,1,rep,name=pwstats,proto3" json:"pwstats,omitempty"`
+	Rejected    map[string]uint64  `protobuf:"bytes,2,rep,name=rejected,proto3" json:"rejected,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Captures    []*CaptureStats    `protobuf:"bytes,3,rep,name=captures,proto3" json:"captures,omitempty"`
+	Subscribers []*SubscriberStats `protobuf:"bytes,4,rep,name=subscribers,proto3" json:"subscribers,omitempty"`
 }
 
-func (x *Request) Reset() {
-	*x = Request{}
+func (x *StatsReply) Reset() {
+	*x = StatsReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_bumstream_proto_msgTypes[0]
+		mi := &file_bumstream_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Request) String() string {
+func (x *StatsReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Request) ProtoMessage() {}
+func (*StatsReply) ProtoMessage() {}
 
-func (x *Request) ProtoReflect() protoreflect.Message {
-	mi := &file_bumstream_proto_msgTypes[0]
+func (x *StatsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -58,62 +1094,108 @@ func (x *Request) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Request.ProtoReflect.Descriptor instead.
-func (*Request) Descriptor() ([]byte, []int) {
-	return file_bumstream_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use StatsReply.ProtoReflect.Descriptor instead.
+func (*StatsReply) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *Request) GetFilter() string {
+func (x *StatsReply) GetPwstats() []*PWStats {
 	if x != nil {
-		return x.Filter
+		return x.Pwstats
 	}
-	return ""
+	return nil
 }
 
-func (x *Request) GetRemote() string {
+func (x *StatsReply) GetRejected() map[string]uint64 {
 	if x != nil {
-		return x.Remote
+		return x.Rejected
 	}
-	return ""
+	return nil
 }
 
-func (x *Request) GetDomain() string {
+func (x *StatsReply) GetCaptures() []*CaptureStats {
 	if x != nil {
-		return x.Domain
+		return x.Captures
 	}
-	return ""
+	return nil
 }
 
-type Packet struct {
+func (x *StatsReply) GetSubscribers() []*SubscriberStats {
+	if x != nil {
+		return x.Subscribers
+	}
+	return nil
+}
+
+type UnknownRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+}
+
+func (x *UnknownRequest) Reset() {
+	*x = UnknownRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-	Data      []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
-	Label     uint32                 `protobuf:"varint,2,opt,name=label,proto3" json:"label,omitempty"`
-	Remote    string                 `protobuf:"bytes,3,opt,name=remote,proto3" json:"remote,omitempty"`
-	Domain    string                 `protobuf:"bytes,4,opt,name=domain,proto3" json:"domain,omitempty"`
-	Peerid    string                 `protobuf:"bytes,5,opt,name=peerid,proto3" json:"peerid,omitempty"`
-	Timestamp *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+func (x *UnknownRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *Packet) Reset() {
-	*x = Packet{}
+func (*UnknownRequest) ProtoMessage() {}
+
+func (x *UnknownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnknownRequest.ProtoReflect.Descriptor instead.
+func (*UnknownRequest) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{10}
+}
+
+type UnknownLabel struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Label     uint32                 `protobuf:"varint,1,opt,name=label,proto3" json:"label,omitempty"`
+	Vni       uint32                 `protobuf:"varint,2,opt,name=vni,proto3" json:"vni,omitempty"`
+	Firstseen *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=firstseen,proto3" json:"firstseen,omitempty"`
+	Lastseen  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=lastseen,proto3" json:"lastseen,omitempty"`
+	Packets   uint64                 `protobuf:"varint,5,opt,name=packets,proto3" json:"packets,omitempty"`
+	Outermacs []string               `protobuf:"bytes,6,rep,name=outermacs,proto3" json:"outermacs,omitempty"`
+	Context   string                 `protobuf:"bytes,7,opt,name=context,proto3" json:"context,omitempty"`
+}
+
+func (x *UnknownLabel) Reset() {
+	*x = UnknownLabel{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_bumstream_proto_msgTypes[1]
+		mi := &file_bumstream_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Packet) String() string {
+func (x *UnknownLabel) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Packet) ProtoMessage() {}
+func (*UnknownLabel) ProtoMessage() {}
 
-func (x *Packet) ProtoReflect() protoreflect.Message {
-	mi := &file_bumstream_proto_msgTypes[1]
+func (x *UnknownLabel) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -124,49 +1206,103 @@ func (x *Packet) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Packet.ProtoReflect.Descriptor instead.
-func (*Packet) Descriptor() ([]byte, []int) {
-	return file_bumstream_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use UnknownLabel.ProtoReflect.Descriptor instead.
+func (*UnknownLabel) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *Packet) GetData() []byte {
+func (x *UnknownLabel) GetLabel() uint32 {
 	if x != nil {
-		return x.Data
+		return x.Label
 	}
-	return nil
+	return 0
 }
 
-func (x *Packet) GetLabel() uint32 {
+func (x *UnknownLabel) GetVni() uint32 {
 	if x != nil {
-		return x.Label
+		return x.Vni
 	}
 	return 0
 }
 
-func (x *Packet) GetRemote() string {
+func (x *UnknownLabel) GetFirstseen() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Remote
+		return x.Firstseen
 	}
-	return ""
+	return nil
 }
 
-func (x *Packet) GetDomain() string {
+func (x *UnknownLabel) GetLastseen() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Domain
+		return x.Lastseen
 	}
-	return ""
+	return nil
 }
 
-func (x *Packet) GetPeerid() string {
+func (x *UnknownLabel) GetPackets() uint64 {
 	if x != nil {
-		return x.Peerid
+		return x.Packets
+	}
+	return 0
+}
+
+func (x *UnknownLabel) GetOutermacs() []string {
+	if x != nil {
+		return x.Outermacs
+	}
+	return nil
+}
+
+func (x *UnknownLabel) GetContext() string {
+	if x != nil {
+		return x.Context
 	}
 	return ""
 }
 
-func (x *Packet) GetTimestamp() *timestamppb.Timestamp {
+type UnknownReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Labels []*UnknownLabel `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"`
+}
+
+func (x *UnknownReply) Reset() {
+	*x = UnknownReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bumstream_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnknownReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnknownReply) ProtoMessage() {}
+
+func (x *UnknownReply) ProtoReflect() protoreflect.Message {
+	mi := &file_bumstream_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnknownReply.ProtoReflect.Descriptor instead.
+func (*UnknownReply) Descriptor() ([]byte, []int) {
+	return file_bumstream_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UnknownReply) GetLabels() []*UnknownLabel {
 	if x != nil {
-		return x.Timestamp
+		return x.Labels
 	}
 	return nil
 }
@@ -177,29 +1313,186 @@ var file_bumstream_proto_rawDesc = []byte{
 	0x0a, 0x0f, 0x62, 0x75, 0x6d, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x12, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
 	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x51, 0x0a, 0x07,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65,
-	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12,
-	0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69,
-	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x22,
-	0xb4, 0x01, 0x0a, 0x06, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
-	0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x14,
-	0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c,
-	0x61, 0x62, 0x65, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06,
-	0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x6f,
-	0x6d, 0x61, 0x69, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x65, 0x72, 0x69, 0x64, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x69, 0x64, 0x12, 0x38, 0x0a, 0x09,
-	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x32, 0x43, 0x0a, 0x0f, 0x42, 0x75, 0x6d, 0x53, 0x6e, 0x69,
-	0x66, 0x66, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x30, 0x0a, 0x05, 0x53, 0x6e, 0x69,
-	0x66, 0x66, 0x12, 0x11, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x22, 0x00, 0x30, 0x01, 0x42, 0x09, 0x5a, 0x07, 0x2e,
-	0x3b, 0x62, 0x75, 0x6d, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xc5, 0x01, 0x0a,
+	0x07, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61,
+	0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e,
+	0x12, 0x28, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74,
+	0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x76,
+	0x6c, 0x61, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x73, 0x76, 0x6c, 0x61, 0x6e,
+	0x12, 0x14, 0x0a, 0x05, 0x63, 0x76, 0x6c, 0x61, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x05, 0x63, 0x76, 0x6c, 0x61, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66,
+	0x61, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x66, 0x61, 0x63, 0x65, 0x22, 0xa8, 0x02, 0x0a, 0x05, 0x4f, 0x75, 0x74, 0x65, 0x72, 0x12, 0x29,
+	0x0a, 0x05, 0x65, 0x6e, 0x63, 0x61, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6e, 0x63, 0x61, 0x70, 0x54, 0x79,
+	0x70, 0x65, 0x52, 0x05, 0x65, 0x6e, 0x63, 0x61, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x6c, 0x61,
+	0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x6c, 0x61, 0x6e, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x72, 0x63, 0x6d, 0x61, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x72, 0x63, 0x6d, 0x61, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x73, 0x74, 0x6d, 0x61,
+	0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x73, 0x74, 0x6d, 0x61, 0x63, 0x12,
+	0x14, 0x0a, 0x05, 0x73, 0x72, 0x63, 0x69, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x73, 0x72, 0x63, 0x69, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x73, 0x74, 0x69, 0x70, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x64, 0x73, 0x74, 0x69, 0x70, 0x12, 0x24, 0x0a, 0x0d, 0x65,
+	0x72, 0x73, 0x70, 0x61, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0d, 0x65, 0x72, 0x73, 0x70, 0x61, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x69, 0x64, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x69, 0x64, 0x12,
+	0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x20, 0x0a,
+	0x0b, 0x67, 0x72, 0x61, 0x6e, 0x75, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0b, 0x67, 0x72, 0x61, 0x6e, 0x75, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x22,
+	0x62, 0x0a, 0x08, 0x42, 0x61, 0x63, 0x6b, 0x62, 0x6f, 0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x72, 0x63, 0x6d, 0x61, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x72, 0x63,
+	0x6d, 0x61, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x73, 0x74, 0x6d, 0x61, 0x63, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x73, 0x74, 0x6d, 0x61, 0x63, 0x12, 0x12, 0x0a, 0x04, 0x62,
+	0x76, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x62, 0x76, 0x69, 0x64, 0x12,
+	0x12, 0x0a, 0x04, 0x69, 0x73, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x69,
+	0x73, 0x69, 0x64, 0x22, 0x8a, 0x01, 0x0a, 0x0f, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x53, 0x74, 0x61,
+	0x63, 0x6b, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x0e, 0x0a,
+	0x02, 0x74, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x74, 0x63, 0x12, 0x16, 0x0a,
+	0x06, 0x62, 0x6f, 0x74, 0x74, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x62,
+	0x6f, 0x74, 0x74, 0x6f, 0x6d, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12, 0x27, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64,
+	0x22, 0xfd, 0x04, 0x0a, 0x06, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64,
+	0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x65, 0x72, 0x69, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x69, 0x64, 0x12, 0x38, 0x0a,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x31, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c,
+	0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x73, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x28, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64,
+	0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x25, 0x0a, 0x05, 0x6f, 0x75,
+	0x74, 0x65, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4f, 0x75, 0x74, 0x65, 0x72, 0x52, 0x05, 0x6f, 0x75, 0x74, 0x65,
+	0x72, 0x12, 0x2f, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x73, 0x69, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x65, 0x73, 0x69, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x6e, 0x69, 0x18, 0x0e, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x03, 0x76, 0x6e, 0x69, 0x12, 0x2e, 0x0a, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x62, 0x6f,
+	0x6e, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x62, 0x6f, 0x6e, 0x65, 0x52, 0x08, 0x62, 0x61,
+	0x63, 0x6b, 0x62, 0x6f, 0x6e, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x76, 0x6c, 0x61, 0x6e, 0x18,
+	0x10, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x73, 0x76, 0x6c, 0x61, 0x6e, 0x12, 0x14, 0x0a, 0x05,
+	0x63, 0x76, 0x6c, 0x61, 0x6e, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x63, 0x76, 0x6c,
+	0x61, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x74, 0x68, 0x65, 0x72, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x12, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x65, 0x74, 0x68, 0x65, 0x72, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x77, 0x6f, 0x72, 0x64, 0x18,
+	0x13, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x77, 0x6f,
+	0x72, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18,
+	0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65,
+	0x22, 0x0e, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x22, 0xd9, 0x01, 0x0a, 0x07, 0x50, 0x57, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f,
+	0x6d, 0x61, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61,
+	0x69, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x6c, 0x6f, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x6c, 0x6f,
+	0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x6f, 0x75, 0x74, 0x6f, 0x66, 0x6f, 0x72, 0x64, 0x65, 0x72,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6f, 0x75, 0x74, 0x6f, 0x66, 0x6f, 0x72, 0x64,
+	0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x22, 0x80, 0x01, 0x0a,
+	0x0c, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1c, 0x0a,
+	0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72,
+	0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72,
+	0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70,
+	0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65,
+	0x64, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x66, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x69, 0x66, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x22,
+	0x3b, 0x0a, 0x0f, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x22, 0xa7, 0x02, 0x0a,
+	0x0a, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2b, 0x0a, 0x07, 0x70,
+	0x77, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x50, 0x57, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52,
+	0x07, 0x70, 0x77, 0x73, 0x74, 0x61, 0x74, 0x73, 0x12, 0x3e, 0x0a, 0x08, 0x72, 0x65, 0x6a, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x2e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08,
+	0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x32, 0x0a, 0x08, 0x63, 0x61, 0x70, 0x74,
+	0x75, 0x72, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x52, 0x08, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x3b, 0x0a, 0x0b,
+	0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x75, 0x62,
+	0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x0b, 0x73, 0x75,
+	0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73, 0x1a, 0x3b, 0x0a, 0x0d, 0x52, 0x65, 0x6a,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x10, 0x0a, 0x0e, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xfa, 0x01, 0x0a, 0x0c, 0x55, 0x6e, 0x6b,
+	0x6e, 0x6f, 0x77, 0x6e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12,
+	0x10, 0x0a, 0x03, 0x76, 0x6e, 0x69, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x76, 0x6e,
+	0x69, 0x12, 0x38, 0x0a, 0x09, 0x66, 0x69, 0x72, 0x73, 0x74, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x09, 0x66, 0x69, 0x72, 0x73, 0x74, 0x73, 0x65, 0x65, 0x6e, 0x12, 0x36, 0x0a, 0x08, 0x6c,
+	0x61, 0x73, 0x74, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x73,
+	0x65, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x1c, 0x0a,
+	0x09, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x6d, 0x61, 0x63, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x09, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x6d, 0x61, 0x63, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x22, 0x3e, 0x0a, 0x0c, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2e, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x52, 0x06, 0x6c,
+	0x61, 0x62, 0x65, 0x6c, 0x73, 0x2a, 0x1f, 0x0a, 0x0a, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x4b,
+	0x69, 0x6e, 0x64, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x41, 0x54, 0x41, 0x10, 0x00, 0x12, 0x07, 0x0a,
+	0x03, 0x4f, 0x41, 0x4d, 0x10, 0x01, 0x2a, 0x4b, 0x0a, 0x09, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4b,
+	0x69, 0x6e, 0x64, 0x12, 0x0d, 0x0a, 0x09, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x50, 0x4f, 0x52, 0x54,
+	0x10, 0x00, 0x12, 0x06, 0x0a, 0x02, 0x50, 0x57, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x4c,
+	0x4f, 0x57, 0x10, 0x02, 0x12, 0x07, 0x0a, 0x03, 0x45, 0x4c, 0x49, 0x10, 0x03, 0x12, 0x0b, 0x0a,
+	0x07, 0x45, 0x4e, 0x54, 0x52, 0x4f, 0x50, 0x59, 0x10, 0x04, 0x12, 0x07, 0x0a, 0x03, 0x45, 0x53,
+	0x49, 0x10, 0x05, 0x2a, 0x21, 0x0a, 0x0b, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x56, 0x50, 0x4c, 0x53, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04,
+	0x45, 0x56, 0x50, 0x4e, 0x10, 0x01, 0x2a, 0x42, 0x0a, 0x09, 0x45, 0x6e, 0x63, 0x61, 0x70, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x45, 0x54, 0x48, 0x45, 0x52, 0x4e, 0x45, 0x54, 0x10,
+	0x00, 0x12, 0x07, 0x0a, 0x03, 0x47, 0x52, 0x45, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x55, 0x44,
+	0x50, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x45, 0x52, 0x53, 0x50, 0x41, 0x4e, 0x10, 0x03, 0x12,
+	0x09, 0x0a, 0x05, 0x56, 0x58, 0x4c, 0x41, 0x4e, 0x10, 0x04, 0x32, 0xbb, 0x01, 0x0a, 0x0f, 0x42,
+	0x75, 0x6d, 0x53, 0x6e, 0x69, 0x66, 0x66, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x30,
+	0x0a, 0x05, 0x53, 0x6e, 0x69, 0x66, 0x66, 0x12, 0x11, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x22, 0x00, 0x30, 0x01,
+	0x12, 0x37, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x07, 0x55, 0x6e, 0x6b,
+	0x6e, 0x6f, 0x77, 0x6e, 0x12, 0x18, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x3b, 0x62, 0x75,
+	0x6d, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -214,21 +1507,57 @@ func file_bumstream_proto_rawDescGZIP() []byte {
 	return file_bumstream_proto_rawDescData
 }
 
-var file_bumstream_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_bumstream_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_bumstream_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_bumstream_proto_goTypes = []interface{}{
-	(*Request)(nil),               // 0: protobuf.Request
-	(*Packet)(nil),                // 1: protobuf.Packet
-	(*timestamppb.Timestamp)(nil), // 2: google.protobuf.Timestamp
+	(PacketKind)(0),               // 0: protobuf.PacketKind
+	(LabelKind)(0),                // 1: protobuf.LabelKind
+	(ServiceType)(0),              // 2: protobuf.ServiceType
+	(EncapType)(0),                // 3: protobuf.EncapType
+	(*Request)(nil),               // 4: protobuf.Request
+	(*Outer)(nil),                 // 5: protobuf.Outer
+	(*Backbone)(nil),              // 6: protobuf.Backbone
+	(*LabelStackEntry)(nil),       // 7: protobuf.LabelStackEntry
+	(*Packet)(nil),                // 8: protobuf.Packet
+	(*StatsRequest)(nil),          // 9: protobuf.StatsRequest
+	(*PWStats)(nil),               // 10: protobuf.PWStats
+	(*CaptureStats)(nil),          // 11: protobuf.CaptureStats
+	(*SubscriberStats)(nil),       // 12: protobuf.SubscriberStats
+	(*StatsReply)(nil),            // 13: protobuf.StatsReply
+	(*UnknownRequest)(nil),        // 14: protobuf.UnknownRequest
+	(*UnknownLabel)(nil),          // 15: protobuf.UnknownLabel
+	(*UnknownReply)(nil),          // 16: protobuf.UnknownReply
+	nil,                           // 17: protobuf.StatsReply.RejectedEntry
+	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
 }
 var file_bumstream_proto_depIdxs = []int32{
-	2, // 0: protobuf.Packet.timestamp:type_name -> google.protobuf.Timestamp
-	0, // 1: protobuf.BumSniffService.Sniff:input_type -> protobuf.Request
-	1, // 2: protobuf.BumSniffService.Sniff:output_type -> protobuf.Packet
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: protobuf.Request.kind:type_name -> protobuf.PacketKind
+	3,  // 1: protobuf.Outer.encap:type_name -> protobuf.EncapType
+	1,  // 2: protobuf.LabelStackEntry.kind:type_name -> protobuf.LabelKind
+	18, // 3: protobuf.Packet.timestamp:type_name -> google.protobuf.Timestamp
+	7,  // 4: protobuf.Packet.labels:type_name -> protobuf.LabelStackEntry
+	0,  // 5: protobuf.Packet.kind:type_name -> protobuf.PacketKind
+	5,  // 6: protobuf.Packet.outer:type_name -> protobuf.Outer
+	2,  // 7: protobuf.Packet.service:type_name -> protobuf.ServiceType
+	6,  // 8: protobuf.Packet.backbone:type_name -> protobuf.Backbone
+	10, // 9: protobuf.StatsReply.pwstats:type_name -> protobuf.PWStats
+	17, // 10: protobuf.StatsReply.rejected:type_name -> protobuf.StatsReply.RejectedEntry
+	11, // 11: protobuf.StatsReply.captures:type_name -> protobuf.CaptureStats
+	12, // 12: protobuf.StatsReply.subscribers:type_name -> protobuf.SubscriberStats
+	18, // 13: protobuf.UnknownLabel.firstseen:type_name -> google.protobuf.Timestamp
+	18, // 14: protobuf.UnknownLabel.lastseen:type_name -> google.protobuf.Timestamp
+	15, // 15: protobuf.UnknownReply.labels:type_name -> protobuf.UnknownLabel
+	4,  // 16: protobuf.BumSniffService.Sniff:input_type -> protobuf.Request
+	9,  // 17: protobuf.BumSniffService.Stats:input_type -> protobuf.StatsRequest
+	14, // 18: protobuf.BumSniffService.Unknown:input_type -> protobuf.UnknownRequest
+	8,  // 19: protobuf.BumSniffService.Sniff:output_type -> protobuf.Packet
+	13, // 20: protobuf.BumSniffService.Stats:output_type -> protobuf.StatsReply
+	16, // 21: protobuf.BumSniffService.Unknown:output_type -> protobuf.UnknownReply
+	19, // [19:22] is the sub-list for method output_type
+	16, // [16:19] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_bumstream_proto_init() }
@@ -250,6 +1579,42 @@ func file_bumstream_proto_init() {
 			}
 		}
 		file_bumstream_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Outer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Backbone); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LabelStackEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Packet); i {
 			case 0:
 				return &v.state
@@ -261,19 +1626,116 @@ func file_bumstream_proto_init() {
 				return nil
 			}
 		}
+		file_bumstream_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PWStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CaptureStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscriberStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnknownRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnknownLabel); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bumstream_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnknownReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_bumstream_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      4,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_bumstream_proto_goTypes,
 		DependencyIndexes: file_bumstream_proto_depIdxs,
+		EnumInfos:         file_bumstream_proto_enumTypes,
 		MessageInfos:      file_bumstream_proto_msgTypes,
 	}.Build()
 	File_bumstream_proto = out.File
@@ -23,6 +23,8 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type BumSniffServiceClient interface {
 	Sniff(ctx context.Context, in *Request, opts ...grpc.CallOption) (BumSniffService_SniffClient, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsReply, error)
+	Unknown(ctx context.Context, in *UnknownRequest, opts ...grpc.CallOption) (*UnknownReply, error)
 }
 
 type bumSniffServiceClient struct {
@@ -65,11 +67,31 @@ func (x *bumSniffServiceSniffClient) Recv() (*Packet, error) {
 	return m, nil
 }
 
+func (c *bumSniffServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsReply, error) {
+	out := new(StatsReply)
+	err := c.cc.Invoke(ctx, "/protobuf.BumSniffService/Stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bumSniffServiceClient) Unknown(ctx context.Context, in *UnknownRequest, opts ...grpc.CallOption) (*UnknownReply, error) {
+	out := new(UnknownReply)
+	err := c.cc.Invoke(ctx, "/protobuf.BumSniffService/Unknown", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BumSniffServiceServer is the server API for BumSniffService service.
 // All implementations should embed UnimplementedBumSniffServiceServer
 // for forward compatibility
 type BumSniffServiceServer interface {
 	Sniff(*Request, BumSniffService_SniffServer) error
+	Stats(context.Context, *StatsRequest) (*StatsReply, error)
+	Unknown(context.Context, *UnknownRequest) (*UnknownReply, error)
 }
 
 // UnimplementedBumSniffServiceServer should be embedded to have forward compatible implementations.
@@ -79,6 +101,12 @@ type UnimplementedBumSniffServiceServer struct {
 func (UnimplementedBumSniffServiceServer) Sniff(*Request, BumSniffService_SniffServer) error {
 	return status.Errorf(codes.Unimplemented, "method Sniff not implemented")
 }
+func (UnimplementedBumSniffServiceServer) Stats(context.Context, *StatsRequest) (*StatsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedBumSniffServiceServer) Unknown(context.Context, *UnknownRequest) (*UnknownReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unknown not implemented")
+}
 
 // UnsafeBumSniffServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to BumSniffServiceServer will
@@ -112,13 +140,58 @@ func (x *bumSniffServiceSniffServer) Send(m *Packet) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _BumSniffService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BumSniffServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.BumSniffService/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BumSniffServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BumSniffService_Unknown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnknownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BumSniffServiceServer).Unknown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.BumSniffService/Unknown",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BumSniffServiceServer).Unknown(ctx, req.(*UnknownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BumSniffService_ServiceDesc is the grpc.ServiceDesc for BumSniffService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var BumSniffService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "protobuf.BumSniffService",
 	HandlerType: (*BumSniffServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Stats",
+			Handler:    _BumSniffService_Stats_Handler,
+		},
+		{
+			MethodName: "Unknown",
+			Handler:    _BumSniffService_Unknown_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Sniff",
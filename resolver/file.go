@@ -0,0 +1,152 @@
+package resolver
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File resolves the key with the mappings in the YAML, JSON or CSV file, which is chosen by the extension.
+//
+// YAML and JSON map the keys to the Info:
+//
+//	label:100:
+//	  domain: bd-100
+//	  remote: pe1
+//
+// CSV has the header of the key and the fields of the Info in any order:
+//
+//	key,domain,remote
+//	label:100,bd-100,pe1
+type File struct {
+	path string
+
+	sync.RWMutex
+	infos map[string]*Info
+}
+
+func NewFile(path string) (*File, error) {
+	f := &File{path: path}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Reload reads the file again. The mappings are kept as they were if the file is invalid.
+func (f *File) Reload() error {
+	r, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var infos map[string]*Info
+	switch ext := strings.ToLower(filepath.Ext(f.path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.NewDecoder(r).Decode(&infos)
+	case ".json":
+		err = json.NewDecoder(r).Decode(&infos)
+	case ".csv":
+		infos, err = readCSV(r)
+	default:
+		return fmt.Errorf("resolver: unsupported file extension %q", ext)
+	}
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("resolver: failed to read %s: %v", f.path, err)
+	}
+
+	f.Lock()
+	f.infos = infos
+	f.Unlock()
+
+	return nil
+}
+
+func (f *File) Resolve(key string) (*Info, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	t, ok := f.infos[key]
+	if !ok || t == nil {
+		return nil, ErrNotFound
+	}
+
+	return t, nil
+}
+
+func (f *File) Preload(fn func(key string, info *Info)) error {
+	f.RLock()
+	defer f.RUnlock()
+
+	for key, t := range f.infos {
+		if t != nil {
+			fn(key, t)
+		}
+	}
+
+	return nil
+}
+
+// readCSV reads the mappings with the header naming the columns.
+func readCSV(r io.Reader) (map[string]*Info, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make(map[string]*Info)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return infos, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var key string
+		t := &Info{}
+		for i, v := range record {
+			if i >= len(header) {
+				break
+			}
+
+			switch strings.ToLower(header[i]) {
+			case "key":
+				key = v
+			case "domain":
+				t.Domain = v
+			case "remote":
+				t.Remote = v
+			case "peerid":
+				t.PeerID = v
+			case "controlword":
+				t.ControlWord = v
+			case "fat":
+				t.FAT = v
+			case "type":
+				t.Type = v
+			case "esi":
+				t.ESI = v
+			}
+		}
+
+		if key == "" {
+			return nil, fmt.Errorf("line %d: key is missing", len(infos)+2)
+		}
+		infos[key] = t
+	}
+}
@@ -0,0 +1,114 @@
+package resolver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testFiles = map[string]string{
+	"labels.yaml": `
+label:100:
+  domain: bd-100
+  remote: pe1
+  controlword: "true"
+  fat: "true"
+vni:5000:
+  domain: bd-5000
+`,
+	"labels.json": `{
+  "label:100": {"domain": "bd-100", "remote": "pe1", "controlword": "true", "fat": "true"},
+  "vni:5000": {"domain": "bd-5000"}
+}`,
+	"labels.csv": `key,remote,domain,controlword,fat
+label:100,pe1,bd-100,true,true
+vni:5000,,bd-5000
+`,
+}
+
+func writeFile(t *testing.T, path, data string) {
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal("Failed to write file:", err)
+	}
+}
+
+func TestFileResolve(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, data := range testFiles {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, data)
+
+		f, err := NewFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+
+		info, err := f.Resolve("label:100")
+		if err != nil {
+			t.Fatalf("The key 'label:100' should be found in %s, but was '%v'", name, err)
+		}
+		if *info != (Info{Domain: "bd-100", Remote: "pe1", ControlWord: "true", FAT: "true"}) {
+			t.Errorf("The key 'label:100' in %s was resolved to %+v", name, info)
+		}
+
+		if info, err := f.Resolve("vni:5000"); err != nil || info.Domain != "bd-5000" {
+			t.Errorf("The key 'vni:5000' in %s should be resolved to 'bd-5000', but was %+v, %v", name, info, err)
+		}
+
+		if _, err := f.Resolve("label:200"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("The key 'label:200' should not be found in %s, but was '%v'", name, err)
+		}
+	}
+}
+
+func TestFileReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.csv")
+	writeFile(t, path, "key,domain\nlabel:100,bd-100\n")
+
+	f, err := NewFile(path)
+	if err != nil {
+		t.Fatal("Failed to read file:", err)
+	}
+
+	writeFile(t, path, "key,domain\nlabel:100,bd-101\nlabel:200,bd-200\n")
+	if err := f.Reload(); err != nil {
+		t.Fatal("Failed to reload file:", err)
+	}
+
+	if info, err := f.Resolve("label:100"); err != nil || info.Domain != "bd-101" {
+		t.Errorf("The key 'label:100' should be resolved to 'bd-101', but was %+v, %v", info, err)
+	}
+	if info, err := f.Resolve("label:200"); err != nil || info.Domain != "bd-200" {
+		t.Errorf("The key 'label:200' should be resolved to 'bd-200', but was %+v, %v", info, err)
+	}
+
+	// The mappings are kept if the file is invalid
+	writeFile(t, path, "key,domain\n,bd-300\n")
+	if err := f.Reload(); err == nil {
+		t.Error("The file without the key should not be reloaded")
+	}
+	if info, err := f.Resolve("label:100"); err != nil || info.Domain != "bd-101" {
+		t.Errorf("The key 'label:100' should be resolved to 'bd-101', but was %+v, %v", info, err)
+	}
+}
+
+func TestFilePreload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.yaml")
+	writeFile(t, path, testFiles["labels.yaml"])
+
+	f, err := NewFile(path)
+	if err != nil {
+		t.Fatal("Failed to read file:", err)
+	}
+
+	infos := make(map[string]*Info)
+	if err := f.Preload(func(key string, info *Info) { infos[key] = info }); err != nil {
+		t.Fatal("Failed to preload file:", err)
+	}
+
+	if len(infos) != 2 || infos["label:100"].Domain != "bd-100" || infos["vni:5000"].Domain != "bd-5000" {
+		t.Errorf("The keys 'label:100' and 'vni:5000' should be preloaded, but was %v", infos)
+	}
+}
@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTP resolves the key with GET <url>/<key> of the HTTP/JSON API, such as the IPAM or the inventory system.
+// The API returns the Info as the JSON object, or 404 if the key is not found.
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTP(rawURL string) *HTTP {
+	return &HTTP{
+		url:    strings.TrimSuffix(rawURL, "/"),
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (h *HTTP) Resolve(key string) (*Info, error) {
+	resp, err := h.client.Get(h.url + "/" + url.PathEscape(key))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("resolver: unexpected status %s for %s", resp.Status, key)
+	}
+
+	t := &Info{}
+	if err := json.NewDecoder(resp.Body).Decode(t); err != nil {
+		return nil, fmt.Errorf("resolver: failed to decode %s: %v", key, err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,39 @@
+package resolver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPResolve(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/labels/label:100", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"domain": "bd-100", "remote": "pe1", "type": "EVPN"}`))
+	})
+	mux.HandleFunc("/labels/label:500", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	h := NewHTTP(ts.URL + "/labels/")
+
+	info, err := h.Resolve("label:100")
+	if err != nil {
+		t.Fatal("Failed to resolve the key 'label:100':", err)
+	}
+	if *info != (Info{Domain: "bd-100", Remote: "pe1", Type: "EVPN"}) {
+		t.Errorf("The key 'label:100' was resolved to %+v", info)
+	}
+
+	if _, err := h.Resolve("label:200"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("The key 'label:200' should not be found, but was '%v'", err)
+	}
+
+	if _, err := h.Resolve("label:500"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("The key 'label:500' should fail, but was '%v'", err)
+	}
+}
@@ -0,0 +1,184 @@
+package resolver
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// InvalidateChannel is the pub/sub channel to publish the keys changed to,
+// which works without the keyspace notifications enabled.
+const InvalidateChannel = "vplsbh:invalidate"
+
+// scanCount is the number of the keys hinted to SCAN at once.
+const scanCount = 1000
+
+// Redis resolves the key with the hash of the same name in Redis.
+type Redis struct {
+	db   int
+	dial func() (redis.Conn, error)
+	pool *redis.Pool
+}
+
+func NewRedis(rawURL string) *Redis {
+	r := &Redis{
+		dial: func() (redis.Conn, error) { return redis.DialURL(rawURL) },
+	}
+	r.pool = &redis.Pool{
+		MaxIdle:     2,
+		MaxActive:   4,
+		IdleTimeout: 5 * time.Minute,
+		Dial:        func() (redis.Conn, error) { return r.dial() },
+	}
+
+	// The database is selected by the path as DialURL does
+	if u, err := url.Parse(rawURL); err == nil {
+		r.db, _ = strconv.Atoi(strings.TrimPrefix(u.Path, "/"))
+	}
+
+	return r
+}
+
+func (r *Redis) Resolve(key string) (*Info, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	return scanInfo(conn.Do("HMGET", key, "Domain", "Remote", "PeerID", "ControlWord", "FAT", "Type", "ESI"))
+}
+
+// scanInfo scans the reply of HMGET into the Info.
+func scanInfo(reply interface{}, err error) (*Info, error) {
+	val, err := redis.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	// HMGET returns nil for every field if the key does not exist
+	if !hasValue(val) {
+		return nil, ErrNotFound
+	}
+
+	t := &Info{}
+	if _, err := redis.Scan(val, &t.Domain, &t.Remote, &t.PeerID, &t.ControlWord, &t.FAT, &t.Type, &t.ESI); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// hasValue tells whether any field is returned by HMGET.
+func hasValue(val []interface{}) bool {
+	for _, v := range val {
+		if v != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch notifies the keys changed by the keyspace notifications, which need the hash and generic events
+// enabled such as "notify-keyspace-events Khg", and by the keys published to InvalidateChannel.
+func (r *Redis) Watch(changed func(key string)) error {
+	// The subscriber has the connection of its own, which is not returned to the pool
+	conn, err := r.dial()
+	if err != nil {
+		return err
+	}
+
+	psc := redis.PubSubConn{Conn: conn}
+	defer psc.Close()
+
+	prefix := fmt.Sprintf("__keyspace@%d__:", r.db)
+	patterns := make([]interface{}, len(keyPrefixes))
+	for i, p := range keyPrefixes {
+		patterns[i] = prefix + p + "*"
+	}
+
+	if err := psc.PSubscribe(patterns...); err != nil {
+		return err
+	}
+	if err := psc.Subscribe(InvalidateChannel); err != nil {
+		return err
+	}
+
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			if v.Pattern != "" {
+				changed(strings.TrimPrefix(v.Channel, prefix))
+			} else {
+				changed(string(v.Data))
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+// KeyspaceEvents returns the keyspace events notified by Redis, which is empty if disabled.
+func (r *Redis) KeyspaceEvents() (string, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	val, err := redis.Strings(conn.Do("CONFIG", "GET", "notify-keyspace-events"))
+	if err != nil {
+		return "", err
+	}
+	if len(val) != 2 {
+		return "", fmt.Errorf("unexpected reply of CONFIG GET: %v", val)
+	}
+
+	return val[1], nil
+}
+
+// Preload scans all the keys to resolve, fetching the hashes of the keys returned at once with the pipeline.
+func (r *Redis) Preload(fn func(key string, info *Info)) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	for _, p := range keyPrefixes {
+		cursor := 0
+		for {
+			val, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", p+"*", "COUNT", scanCount))
+			if err != nil {
+				return err
+			}
+
+			var keys []string
+			if _, err := redis.Scan(val, &cursor, &keys); err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				conn.Send("HMGET", key, "Domain", "Remote", "PeerID", "ControlWord", "FAT", "Type", "ESI")
+			}
+			if err := conn.Flush(); err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				t, err := scanInfo(conn.Receive())
+				switch err.(type) {
+				case nil:
+					fn(key, t)
+				case redis.Error:
+					// Skip the key which is not a hash
+				default:
+					if err != ErrNotFound {
+						return err
+					}
+				}
+			}
+
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+
+	return nil
+}
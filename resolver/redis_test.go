@@ -0,0 +1,232 @@
+package resolver
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// fakeRedis keeps the hashes and the strings in memory and replies to the commands used by Redis.
+type fakeRedis struct {
+	sync.Mutex
+	hashes   map[string]map[string]string
+	strings  map[string]string
+	pageSize int
+	scans    int
+
+	subscribed []string
+	messages   chan []interface{}
+}
+
+// fakeConn replies to the commands with fakeRedis, keeping the replies of the commands sent until received.
+type fakeConn struct {
+	r       *fakeRedis
+	pending []interface{}
+}
+
+func newTestRedis(fr *fakeRedis) *Redis {
+	r := NewRedis("redis://localhost:6379/2")
+	r.dial = func() (redis.Conn, error) { return &fakeConn{r: fr}, nil }
+	return r
+}
+
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Err() error   { return nil }
+func (c *fakeConn) Flush() error { return nil }
+
+func (c *fakeConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if cmd == "" {
+		c.pending = nil
+		return nil, nil
+	}
+
+	c.Send(cmd, args...)
+	return c.Receive()
+}
+
+func (c *fakeConn) Send(cmd string, args ...interface{}) error {
+	c.pending = append(c.pending, c.r.exec(cmd, args...)...)
+	return nil
+}
+
+// Receive returns the replies of the commands sent, and then the messages published to the subscriber.
+func (c *fakeConn) Receive() (interface{}, error) {
+	var reply interface{}
+	if len(c.pending) > 0 {
+		reply, c.pending = c.pending[0], c.pending[1:]
+	} else {
+		m, ok := <-c.r.messages
+		if !ok {
+			return nil, io.EOF
+		}
+		reply = m
+	}
+
+	if err, ok := reply.(redis.Error); ok {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// exec returns the replies of the command, which are more than one for SUBSCRIBE and PSUBSCRIBE.
+func (r *fakeRedis) exec(cmd string, args ...interface{}) []interface{} {
+	r.Lock()
+	defer r.Unlock()
+
+	switch strings.ToUpper(cmd) {
+	case "HMGET":
+		key := args[0].(string)
+		if _, ok := r.strings[key]; ok {
+			return []interface{}{redis.Error("WRONGTYPE Operation against a key holding the wrong kind of value")}
+		}
+
+		var val []interface{}
+		for _, f := range args[1:] {
+			if v, ok := r.hashes[key][f.(string)]; ok {
+				val = append(val, []byte(v))
+			} else {
+				val = append(val, nil)
+			}
+		}
+		return []interface{}{val}
+	case "SCAN":
+		r.scans++
+		cursor, prefix := args[0].(int), strings.TrimSuffix(args[2].(string), "*")
+
+		var keys []string
+		for k := range r.hashes {
+			keys = append(keys, k)
+		}
+		for k := range r.strings {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		// The page may have no key matching the pattern, as SCAN filters the keys after scanning
+		next := cursor + r.pageSize
+		if next >= len(keys) {
+			next = 0
+		}
+		var page []interface{}
+		for i := cursor; i < len(keys) && i < cursor+r.pageSize; i++ {
+			if strings.HasPrefix(keys[i], prefix) {
+				page = append(page, []byte(keys[i]))
+			}
+		}
+		return []interface{}{[]interface{}{[]byte(strconv.Itoa(next)), page}}
+	case "PSUBSCRIBE", "SUBSCRIBE":
+		var replies []interface{}
+		for _, ch := range args {
+			r.subscribed = append(r.subscribed, ch.(string))
+			replies = append(replies, []interface{}{[]byte(strings.ToLower(cmd)), []byte(ch.(string)), int64(len(r.subscribed))})
+		}
+		return replies
+	default:
+		return []interface{}{redis.Error("ERR unknown command '" + cmd + "'")}
+	}
+}
+
+func TestRedisResolve(t *testing.T) {
+	fr := &fakeRedis{
+		hashes: map[string]map[string]string{
+			"label:100": {"Domain": "bd-100", "Remote": "pe1", "ControlWord": "true", "FAT": "true"},
+		},
+		strings: map[string]string{"label:300": "bd-300"},
+	}
+	r := newTestRedis(fr)
+
+	info, err := r.Resolve("label:100")
+	if err != nil {
+		t.Fatal("Failed to resolve the key 'label:100':", err)
+	}
+	if *info != (Info{Domain: "bd-100", Remote: "pe1", ControlWord: "true", FAT: "true"}) {
+		t.Errorf("The key 'label:100' was resolved to %+v", info)
+	}
+
+	// HMGET returns nil for every field of the key not found
+	if _, err := r.Resolve("label:200"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("The key 'label:200' should not be found, but was '%v'", err)
+	}
+
+	if _, err := r.Resolve("label:300"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("The key 'label:300' should fail, but was '%v'", err)
+	}
+}
+
+func TestRedisWatch(t *testing.T) {
+	fr := &fakeRedis{messages: make(chan []interface{}, 10)}
+	r := newTestRedis(fr)
+
+	fr.messages <- []interface{}{[]byte("pmessage"), []byte("__keyspace@2__:label:*"), []byte("__keyspace@2__:label:100"), []byte("hset")}
+	fr.messages <- []interface{}{[]byte("pmessage"), []byte("__keyspace@2__:mac:*"), []byte("__keyspace@2__:mac:cc:13:14:64:00:01"), []byte("del")}
+	fr.messages <- []interface{}{[]byte("message"), []byte(InvalidateChannel), []byte("vni:5000")}
+	close(fr.messages)
+
+	var keys []string
+	err := r.Watch(func(key string) {
+		keys = append(keys, key)
+	})
+	if err != io.EOF {
+		t.Errorf("The watch should end with the connection, but was '%v'", err)
+	}
+
+	expected := []string{"label:100", "mac:cc:13:14:64:00:01", "vni:5000"}
+	if strings.Join(keys, " ") != strings.Join(expected, " ") {
+		t.Errorf("The keys changed should be '%v', but was '%v'", expected, keys)
+	}
+
+	// The keyspace of the database selected by the URL is subscribed with the invalidate channel
+	subscribed := strings.Join(fr.subscribed, " ")
+	for _, ch := range []string{"__keyspace@2__:label:*", "__keyspace@2__:vni:*", InvalidateChannel} {
+		if !strings.Contains(subscribed, ch) {
+			t.Errorf("The channel '%s' should be subscribed, but was '%v'", ch, fr.subscribed)
+		}
+	}
+}
+
+func TestRedisPreload(t *testing.T) {
+	fr := &fakeRedis{
+		hashes: map[string]map[string]string{
+			"label:100": {"Domain": "bd-100"},
+			"label:101": {"Domain": "bd-101"},
+			"label:102": {"Domain": "bd-102"},
+			"label:103": {"Domain": "bd-103"},
+			"vni:5000":  {"Domain": "bd-5000"},
+			"other:1":   {"Domain": "other"},
+		},
+		strings:  map[string]string{"label:104": "bd-104"},
+		pageSize: 2,
+	}
+	r := newTestRedis(fr)
+
+	infos := make(map[string]*Info)
+	err := r.Preload(func(key string, info *Info) {
+		infos[key] = info
+	})
+	if err != nil {
+		t.Fatal("Failed to preload:", err)
+	}
+
+	if len(infos) != 5 {
+		t.Errorf("The number of the keys preloaded should be '5', but was '%d'", len(infos))
+	}
+	for key, domain := range map[string]string{"label:100": "bd-100", "label:103": "bd-103", "vni:5000": "bd-5000"} {
+		if info, ok := infos[key]; !ok || info.Domain != domain {
+			t.Errorf("The key '%s' should be preloaded with '%s', but was '%+v'", key, domain, info)
+		}
+	}
+	if _, ok := infos["label:104"]; ok {
+		t.Error("The key 'label:104' which is not a hash should be skipped")
+	}
+
+	// The 7 keys are scanned in 4 pages for each of the prefixes
+	if fr.scans != 4*len(keyPrefixes) {
+		t.Errorf("The number of SCAN should be '%d', but was '%d'", 4*len(keyPrefixes), fr.scans)
+	}
+}
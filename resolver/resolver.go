@@ -0,0 +1,74 @@
+// Package resolver resolves the labels of the mirrored frames to their domains and remotes.
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrNotFound is returned when the key is not found by the resolver.
+var ErrNotFound = errors.New("resolver: key not found")
+
+// Info is the attributes of the label, the VNI, the VTEP, the I-SID or the outer MAC.
+type Info struct {
+	Domain      string `json:"domain"      yaml:"domain"`
+	Remote      string `json:"remote"      yaml:"remote"`
+	PeerID      string `json:"peerid"      yaml:"peerid"`
+	ControlWord string `json:"controlword" yaml:"controlword"`
+	FAT         string `json:"fat"         yaml:"fat"`
+	Type        string `json:"type"        yaml:"type"`
+	ESI         string `json:"esi"         yaml:"esi"`
+}
+
+// Resolver resolves the attributes of the key, which is one of "label:<label>", "label:<context>:<label>",
+// "vni:<vni>", "vtep:<address>", "isid:<isid>" and "mac:<address>".
+// The context tells the PE which assigned the label, such as the interface, the outer MAC or the LDP neighbor.
+type Resolver interface {
+	Resolve(key string) (*Info, error)
+}
+
+// Reloader is the resolver which can reload its mappings, such as from the file.
+type Reloader interface {
+	Reload() error
+}
+
+// Watcher is the resolver which notifies the keys changed, such as by re-provisioning the PW.
+// Watch blocks calling changed for the keys until the watch fails.
+type Watcher interface {
+	Watch(changed func(key string)) error
+}
+
+// Preloader is the resolver which can enumerate all of its mappings to warm up the cache.
+type Preloader interface {
+	Preload(fn func(key string, info *Info)) error
+}
+
+// keyPrefixes is the prefixes of the keys to resolve.
+var keyPrefixes = []string{"label:", "vni:", "vtep:", "isid:", "mac:"}
+
+// Open returns the resolver for the URL.
+// redis:// and rediss:// are resolved with Redis, http:// and https:// with the HTTP/JSON API,
+// and file:// or the path without the scheme with the YAML, JSON or CSV file.
+func Open(rawURL string) (Resolver, error) {
+	if !strings.Contains(rawURL, "://") {
+		return NewFile(rawURL)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "redis", "rediss":
+		return NewRedis(rawURL), nil
+	case "http", "https":
+		return NewHTTP(rawURL), nil
+	case "file":
+		return NewFile(u.Path)
+	default:
+		return nil, fmt.Errorf("resolver: unsupported scheme %q", u.Scheme)
+	}
+}